@@ -0,0 +1,238 @@
+package gocsv
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//manifestFile name of manifest in bundle
+const manifestFile = "manifest.json"
+
+//Manifest tables of bundle
+type Manifest struct {
+	Version string          `json:"version"`
+	Hash    string          `json:"hash"` //sha256 of all table names and hashes
+	Tables  []ManifestTable `json:"tables"`
+}
+
+//ManifestTable table of bundle
+type ManifestTable struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Hash string `json:"hash"` //sha256 of utf8 content
+	Rows int    `json:"rows"`
+}
+
+//Table table of name
+func (m *Manifest) Table(name string) (ManifestTable, bool) {
+	for _, t := range m.Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return ManifestTable{}, false
+}
+
+//sum hash of all tables, tables are sorted by name
+func (m *Manifest) sum() string {
+	h := sha256.New()
+	for _, t := range m.Tables {
+		fmt.Fprintf(h, "%v:%v:%v\n", t.Name, t.Hash, t.Rows)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//Bundle tables of a zip bundle, they are verified by manifest
+type Bundle struct {
+	Manifest Manifest
+	contents map[string][]byte //name => utf8 content
+}
+
+//WriteBundle write csv files of dir to zip bundle file, contents are utf8 in bundle
+func WriteBundle(dir string, isGbk bool, version string, file string, opts ...Option) (*Manifest, error) {
+	fi, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+	m, err := WriteBundleTo(fi, dir, isGbk, version, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return m, fi.Close()
+}
+
+//WriteBundleTo write csv files of dir to w as zip bundle, each table is checked by reading its rows
+func WriteBundleTo(w io.Writer, dir string, isGbk bool, version string, opts ...Option) (*Manifest, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Version: version, Tables: make([]ManifestTable, 0)}
+	contents := make(map[string][]byte)
+	for _, info := range infos {
+		if info.IsDir() || strings.ToLower(filepath.Ext(info.Name())) != ".csv" {
+			continue
+		}
+		content, err := readContent(filepath.Join(dir, info.Name()), isGbk)
+		if err != nil {
+			return nil, err
+		}
+		rows := 0
+		ropts := append(append([]Option{}, opts...), func(o *options) {
+			o.name = filepath.Join(dir, info.Name())
+		})
+		err = ReadRawContext(context.Background(), bytes.NewReader(content), func(fields []Field) error {
+			rows++
+			return nil
+		}, ropts...)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+		sum := sha256.Sum256(content)
+		m.Tables = append(m.Tables, ManifestTable{Name: name, File: info.Name(), Hash: hex.EncodeToString(sum[:]), Rows: rows})
+		contents[info.Name()] = content
+	}
+	sort.Slice(m.Tables, func(i, j int) bool {
+		return m.Tables[i].Name < m.Tables[j].Name
+	})
+	m.Hash = m.sum()
+
+	zw := zip.NewWriter(w)
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, manifestFile, manifest); err != nil {
+		return nil, err
+	}
+	for _, t := range m.Tables {
+		if err := writeZipFile(zw, t.File, contents[t.File]); err != nil {
+			return nil, err
+		}
+	}
+	return m, zw.Close()
+}
+
+//OpenBundle open zip bundle file and verify hashes of all tables
+func OpenBundle(file string) (*Bundle, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ReadBundle(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("open bundle: %v, error: %v", file, err))
+	}
+	return b, nil
+}
+
+//ReadBundle read zip bundle and verify hashes of all tables
+func ReadBundle(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		content, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name] = content
+	}
+	manifest, ok := files[manifestFile]
+	if !ok {
+		return nil, errors.New("Bundle has no " + manifestFile)
+	}
+	b := &Bundle{contents: make(map[string][]byte)}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, err
+	}
+	for _, t := range b.Manifest.Tables {
+		content, ok := files[t.File]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Bundle has no file %v of table %v", t.File, t.Name))
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != t.Hash {
+			return nil, errors.New(fmt.Sprintf("Bundle table %v hash mismatch, want %v", t.Name, t.Hash))
+		}
+		b.contents[t.Name] = content
+	}
+	if b.Manifest.sum() != b.Manifest.Hash {
+		return nil, errors.New(fmt.Sprintf("Bundle hash mismatch, want %v", b.Manifest.Hash))
+	}
+	return b, nil
+}
+
+//Open utf8 content of table
+func (b *Bundle) Open(name string) (io.Reader, error) {
+	content, ok := b.contents[name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Bundle has no table %v", name))
+	}
+	return bytes.NewReader(content), nil
+}
+
+//ReadList read table for []struct
+func (b *Bundle) ReadList(name string, out interface{}, opts ...Option) error {
+	r, err := b.Open(name)
+	if err != nil {
+		return err
+	}
+	return ReadListContext(context.Background(), r, out, opts...)
+}
+
+//ReadMap read table for map[interface{}]struct
+func (b *Bundle) ReadMap(name string, keyField string, out interface{}, opts ...Option) error {
+	r, err := b.Open(name)
+	if err != nil {
+		return err
+	}
+	return ReadMapContext(context.Background(), r, keyField, out, opts...)
+}
+
+//readContent utf8 content of file
+func readContent(file string, isGbk bool) ([]byte, error) {
+	fi, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+	if !isGbk {
+		return ioutil.ReadAll(fi)
+	}
+	return ioutil.ReadAll(gbkReader(fi))
+}
+
+func writeZipFile(zw *zip.Writer, name string, content []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
@@ -0,0 +1,116 @@
+package gocsv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const bundleCat = "a,b\nid,name\nint,string\n1,phone\n2,pad\n"
+
+func writeTestBundle(t *testing.T) []byte {
+	dir := writeFiles(t, map[string]string{"cat.csv": bundleCat, "mob.csv": "a\nid\nint\n10\n", "notes.txt": "x"})
+	var buf bytes.Buffer
+	m, err := WriteBundleTo(&buf, dir, false, "1.0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tables) != 2 || m.Tables[0].Name != "cat" || m.Tables[0].Rows != 2 || m.Tables[1].Rows != 1 {
+		t.Fatalf("manifest: %+v", m)
+	}
+	return buf.Bytes()
+}
+
+//rezip copy zip of content with files changed by edit
+func rezip(t *testing.T, content []byte, edit func(name string, content []byte) []byte) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		old, err := readZipFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeZipFile(zw, f.Name, edit(f.Name, old)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBundle(t *testing.T) {
+	content := writeTestBundle(t)
+	b, err := ReadBundle(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Manifest.Version != "1.0.3" || b.Manifest.Hash != b.Manifest.sum() {
+		t.Fatalf("manifest: %+v", b.Manifest)
+	}
+	var cats []setCat
+	if err := b.ReadList("cat", &cats); err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) != 2 || cats[1].Name != "pad" {
+		t.Fatalf("cats: %+v", cats)
+	}
+	m := make(map[int]setCat)
+	if err := b.ReadMap("cat", "id", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m[1].Name != "phone" {
+		t.Fatalf("map: %+v", m)
+	}
+	if _, err := b.Open("notes"); err == nil {
+		t.Fatal("file of other extension is bundled")
+	}
+}
+
+func TestBundleTampered(t *testing.T) {
+	content := writeTestBundle(t)
+	for want, edit := range map[string]func(name string, content []byte) []byte{
+		"Bundle table cat hash mismatch": func(name string, content []byte) []byte {
+			if name == "cat.csv" {
+				return bytes.Replace(content, []byte("phone"), []byte("phon3"), 1)
+			}
+			return content
+		},
+		"Bundle hash mismatch": func(name string, content []byte) []byte {
+			if name != manifestFile {
+				return content
+			}
+			var m Manifest
+			if err := json.Unmarshal(content, &m); err != nil {
+				t.Fatal(err)
+			}
+			m.Tables[0].Rows = 3
+			out, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return out
+		},
+	} {
+		tampered := rezip(t, content, edit)
+		_, err := ReadBundle(bytes.NewReader(tampered), int64(len(tampered)))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("error: %v, want: %v", err, want)
+		}
+	}
+}
+
+func TestBundleInvalidTable(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"cat.csv": "a,b\nid,name\nint!,string\n,phone\n"})
+	var buf bytes.Buffer
+	if _, err := WriteBundleTo(&buf, dir, false, "1"); err == nil {
+		t.Fatal("table breaking constraints is bundled")
+	}
+}
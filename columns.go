@@ -0,0 +1,71 @@
+package gocsv
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ColumnError csv columns and struct fields are mismatched
+type ColumnError struct {
+	Name    string   //file name
+	Unknown []string //csv columns without struct field
+	Missing []string //required struct fields without csv column
+}
+
+func (e *ColumnError) Error() string {
+	msgs := make([]string, 0, 2)
+	if len(e.Unknown) > 0 {
+		msgs = append(msgs, fmt.Sprintf("unknown columns: %v", strings.Join(e.Unknown, ", ")))
+	}
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("missing columns: %v", strings.Join(e.Missing, ", ")))
+	}
+	if e.Name == "" {
+		return fmt.Sprintf("Csv columns mismatch, %v", strings.Join(msgs, "; "))
+	}
+	return fmt.Sprintf("Csv %v columns mismatch, %v", e.Name, strings.Join(msgs, "; "))
+}
+
+//DisallowUnknownColumns return error if csv has columns without struct field
+func DisallowUnknownColumns() Option {
+	return func(o *options) {
+		o.disallowUnknown = true
+	}
+}
+
+//WithColumnWarning call fn instead of returning error when columns mismatch,
+//it reports unknown columns too
+func WithColumnWarning(fn func(*ColumnError)) Option {
+	return func(o *options) {
+		o.columnWarning = fn
+	}
+}
+
+//checkColumns check csv columns against struct fields
+func (d *elemDecoder) checkColumns(h *header, o *options) error {
+	cerr := &ColumnError{Name: o.name}
+	//rest field consumes all columns
+	if d.rest < 0 && (o.disallowUnknown || o.columnWarning != nil) {
+		for _, name := range h.names {
+			if name == "" {
+				continue
+			}
+			if _, ok := d.index(name); !ok {
+				cerr.Unknown = append(cerr.Unknown, name)
+			}
+		}
+	}
+	for i, tag := range d.tags {
+		if tag.required && !tag.rest && !d.hasColumn(h.names, i) {
+			cerr.Missing = append(cerr.Missing, tag.name)
+		}
+	}
+	if len(cerr.Unknown) == 0 && len(cerr.Missing) == 0 {
+		return nil
+	}
+	if o.columnWarning != nil {
+		o.columnWarning(cerr)
+		return nil
+	}
+	return cerr
+}
@@ -0,0 +1,158 @@
+package gocsv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//constraint constraint of column declared in kind row, e.g. "int(1..100) unique", "string!"
+type constraint struct {
+	required bool
+	unique   bool
+	ref      string //"table.column" of foreign key
+	rng      string //"1..100", "1..", "..100"
+	min, max *float64
+}
+
+//parseKind parse kind row value to kind and constraint
+func parseKind(value string) (string, constraint, error) {
+	c := constraint{}
+	tokens := strings.Fields(value)
+	if len(tokens) == 0 {
+		return "string", c, nil
+	}
+	kind := tokens[0]
+	//外键：ref:category.id 类型同字段类型
+	if strings.HasPrefix(kind, "ref:") {
+		tokens = append([]string{""}, tokens...)
+		kind = ""
+	}
+	if strings.HasSuffix(kind, "!") {
+		kind = strings.TrimSuffix(kind, "!")
+		c.required = true
+	}
+	//范围：int(1..100)
+	if i := strings.Index(kind, "("); i > 0 && strings.HasSuffix(kind, ")") && strings.Contains(kind[i:], "..") {
+		rng := kind[i+1 : len(kind)-1]
+		bounds := strings.SplitN(rng, "..", 2)
+		for j, bound := range bounds {
+			if bound == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(bound, 64)
+			if err != nil {
+				return kind, c, errors.New(fmt.Sprintf("Kind \"%v\" has invalid range \"%v\"", value, rng))
+			}
+			if j == 0 {
+				c.min = &n
+			} else {
+				c.max = &n
+			}
+		}
+		c.rng = rng
+		kind = kind[:i]
+	}
+	for _, token := range tokens[1:] {
+		if strings.HasPrefix(token, "ref:") {
+			c.ref = strings.TrimPrefix(token, "ref:")
+			if strings.Count(c.ref, ".") != 1 || strings.HasPrefix(c.ref, ".") || strings.HasSuffix(c.ref, ".") {
+				return kind, c, errors.New(fmt.Sprintf("Kind \"%v\" has invalid reference \"%v\", it must be ref:table.column", value, token))
+			}
+			continue
+		}
+		switch token {
+		case "unique":
+			c.unique = true
+		case "required", "!":
+			c.required = true
+		default:
+			return kind, c, errors.New(fmt.Sprintf("Kind \"%v\" has unknown constraint \"%v\"", value, token))
+		}
+	}
+	if kind == "" && c.ref == "" {
+		kind = "string"
+	}
+	return kind, c, nil
+}
+
+//check value of kind, unique is checked by caller
+func (c constraint) check(kind string, value string) string {
+	if c.required && value == "" {
+		return "required"
+	}
+	//enum kind or array of enum kind
+	if k, err := LookupKind(kind); err == nil && strings.Contains(k.Name, "enum(") {
+		if _, err := k.Parse(value); err != nil {
+			return kind
+		}
+	}
+	//empty value of optional column has no range
+	if c.min == nil && c.max == nil || value == "" {
+		return ""
+	}
+	n := float64(utf8.RuneCountInString(value))
+	if isNumber(kind) {
+		var err error
+		if n, err = parseFloat(value); err != nil {
+			return kind
+		}
+	}
+	if (c.min != nil && n < *c.min) || (c.max != nil && n > *c.max) {
+		return fmt.Sprintf("%v(%v)", kind, c.rng)
+	}
+	return ""
+}
+
+//checker check constraints of header for each row
+type checker struct {
+	name    string
+	h       *header
+	uniques map[int]map[string]int //column => value => line
+}
+
+func newChecker(name string, h *header) *checker {
+	uniques := make(map[int]map[string]int)
+	for i, c := range h.constraints {
+		if c.unique {
+			uniques[i] = make(map[string]int)
+		}
+	}
+	return &checker{name: name, h: h, uniques: uniques}
+}
+
+//check fields of row
+func (c *checker) check(r row) error {
+	for i, f := range r.fields {
+		if rule := c.h.constraints[i].check(f.Kind, f.Value); rule != "" {
+			return &ValidationError{Name: c.name, Line: r.line, Column: f.Name, Value: f.Value, Rule: rule}
+		}
+		lines, ok := c.uniques[i]
+		if !ok {
+			continue
+		}
+		if line, ok := lines[f.Value]; ok {
+			return &ValidationError{Name: c.name, Line: r.line, Column: f.Name, Value: f.Value, Rule: fmt.Sprintf("unique (same as line %v)", line)}
+		}
+		lines[f.Value] = r.line
+	}
+	return nil
+}
+
+//Validate read csv file and check constraints of kind row
+func Validate(file string, isGbk bool, opts ...Option) error {
+	return ReadRaw(file, isGbk, func(fields []Field) error {
+		return nil
+	}, opts...)
+}
+
+//ValidateContext read csv from r and check constraints of kind row
+func ValidateContext(ctx context.Context, r io.Reader, opts ...Option) error {
+	return ReadRawContext(ctx, r, func(fields []Field) error {
+		return nil
+	}, opts...)
+}
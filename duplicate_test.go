@@ -0,0 +1,93 @@
+package gocsv
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type dupMob struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+}
+
+const dupCsv = "a,b\nid,name\nint,string\n1,a\n2,b\n1,c\n3,d\n2,e\n"
+
+func TestDuplicateError(t *testing.T) {
+	m := make(map[int]dupMob)
+	err := ReadMapContext(context.Background(), strings.NewReader(dupCsv), "id", &m)
+	if err == nil || !strings.Contains(err.Error(), `Duplicate primary key "1" at line 4 and line 6`) {
+		t.Fatalf("error: %v", err)
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	for _, tt := range []struct {
+		policy DuplicatePolicy
+		want   map[int]string
+	}{
+		{DuplicateFirst, map[int]string{1: "a", 2: "b", 3: "d"}},
+		{DuplicateLast, map[int]string{1: "c", 2: "e", 3: "d"}},
+	} {
+		m := make(map[int]*dupMob)
+		if err := ReadMapContext(context.Background(), strings.NewReader(dupCsv), "id", &m, WithDuplicate(tt.policy)); err != nil {
+			t.Fatal(err)
+		}
+		names := make(map[int]string)
+		for id, mob := range m {
+			names[id] = mob.Name
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Fatalf("policy %v names: %v, want: %v", tt.policy, names, tt.want)
+		}
+	}
+}
+
+func TestDuplicateGroup(t *testing.T) {
+	m := make(map[int][]dupMob)
+	if err := ReadMapContext(context.Background(), strings.NewReader(dupCsv), "id", &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m[1]) != 2 || m[1][0].Name != "a" || m[1][1].Name != "c" || len(m[3]) != 1 {
+		t.Fatalf("map: %+v", m)
+	}
+	one := make(map[int]dupMob)
+	if err := ReadMapContext(context.Background(), strings.NewReader(dupCsv), "id", &one, WithDuplicate(DuplicateGroup)); err == nil {
+		t.Fatal("DuplicateGroup of map without slice element is accepted")
+	}
+}
+
+func TestDuplicateExisting(t *testing.T) {
+	//entries already in the map are overwritten, they are not duplicates
+	m := map[int]dupMob{1: {ID: 1, Name: "old"}, 9: {ID: 9, Name: "kept"}}
+	csv := "a,b\nid,name\nint,string\n1,a\n"
+	if err := ReadMapContext(context.Background(), strings.NewReader(csv), "id", &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[1].Name != "a" || m[9].Name != "kept" {
+		t.Fatalf("map: %+v", m)
+	}
+}
+
+func TestDuplicateObserver(t *testing.T) {
+	//DuplicateLast replaces the observed row of the first key
+	var replaces []int
+	var names []string
+	watch := func(o *options) {
+		o.observer = &observer{
+			header: func(h *header) {},
+			row: func(r row, elmv reflect.Value, replace int) {
+				replaces = append(replaces, replace)
+				names = append(names, elmv.Interface().(dupMob).Name)
+			},
+		}
+	}
+	m := make(map[int]dupMob)
+	if err := ReadMapContext(context.Background(), strings.NewReader(dupCsv), "id", &m, WithDuplicate(DuplicateLast), watch); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{-1, -1, 0, -1, 1}; !reflect.DeepEqual(replaces, want) {
+		t.Fatalf("replaces: %v, want: %v, names: %v", replaces, want, names)
+	}
+}
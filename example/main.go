@@ -21,7 +21,6 @@ func main() {
 	data, err := gocsv.Read("datautf8.csv", false)
 	if err != nil {
 		panic(fmt.Sprintf("read error: %v", err))
-		return
 	}
 	fmt.Printf("%#v\n", data)
 
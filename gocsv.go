@@ -2,14 +2,13 @@ package gocsv
 
 import (
 	"fmt"
-	"encoding/csv"
 	"errors"
 	"strconv"
 	"os"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/encoding/simplifiedchinese"
 	"reflect"
 	"strings"
+	"context"
+	"io"
 )
 
 //Field field info
@@ -21,16 +20,30 @@ type Field struct {
 
 
 //Read read for map array
-func Read(file string, isGbk bool) (list []map[string]interface{}, err error) {
+func Read(file string, isGbk bool, opts ...Option) (list []map[string]interface{}, err error) {
 	//catch panic
 	defer func() {
 		if rerr := recover(); rerr != nil {
 			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
 		}
 	}()
+	return read(fileSource(file), fileOptions(file, isGbk, opts))
+}
 
+//ReadContext read for map array from r
+func ReadContext(ctx context.Context, r io.Reader, opts ...Option) (list []map[string]interface{}, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return read(streamSource(r), streamOptions(ctx, opts))
+}
+
+func read(src rowSource, o *options) (list []map[string]interface{}, err error) {
 	list = make([]map[string]interface{}, 0);
-	err = ReadRaw(file, isGbk, func(fields []Field) error {
+	err = src(o, nil, fieldsHandle(func(fields []Field) error {
 		item := make(map[string]interface{})
 		for _, f := range fields {
 			if len(f.Name) <= 0 {
@@ -50,25 +63,49 @@ func Read(file string, isGbk bool) (list []map[string]interface{}, err error) {
 					itemValue = 0
 				}
 			default:
+				//bool, arrays and other kinds of WithTypedValues, e.g. "[]int" is []int
 				itemValue = f.Value
+				if !o.typed {
+					break
+				}
+				if k, kerr := LookupKind(f.Kind); kerr == nil {
+					itemValue, innerr = k.Parse(f.Value)
+					if innerr != nil {
+						itemValue = reflect.Zero(k.Type).Interface()
+					}
+				}
 			}
 			item[f.Name] = itemValue
 		}
 		list = append(list, item)
 		return nil
-	})
+	}))
 	return list, err
 }
 
 //ReadList read for []struct
-func ReadList(file string, isGbk bool, out interface{}) (err error) {
+func ReadList(file string, isGbk bool, out interface{}, opts ...Option) (err error) {
 	//catch panic
 	defer func() {
 		if rerr := recover(); rerr != nil {
 			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
 		}
 	}()
+	return readList(fileSource(file), fileOptions(file, isGbk, opts), out)
+}
 
+//ReadListContext read for []struct from r
+func ReadListContext(ctx context.Context, r io.Reader, out interface{}, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return readList(streamSource(r), streamOptions(ctx, opts), out)
+}
+
+func readList(src rowSource, o *options, out interface{}) (err error) {
 	if out == nil {
 		return errors.New("Cannot remake from <nil>")
 	}
@@ -97,29 +134,19 @@ func ReadList(file string, isGbk bool, out interface{}) (err error) {
 		elmIsPtr = true
 	}
 
-	//map field => value
-	idxs := make(map[string]int)
-
-	for i := 0; i < elmt.NumField(); i++ {
-		name := elmt.Field(i).Tag.Get("csv")
-		if len(name) <= 0 {
-			name = elmt.Field(i).Name
-		}
-		idxs[format(name)] = i
+	dec, err := newElemDecoder(elmt, o.mapper)
+	if err != nil {
+		return err
 	}
-
-	err = ReadRaw(file, isGbk, func(fields []Field) error {
-		elmv := reflect.Indirect(reflect.New(elmt))
-		for _, f := range fields {
-			if len(f.Name) <= 0 {
-				continue
-			}
-			idx, ok := idxs[format(f.Name)]
-			if !ok {
-				continue
-			}
-			fValue := elmv.Field(idx)
-			setValue(&fValue, f)
+	onHeader := func(h *header) error {
+		return dec.checkColumns(h, o)
+	}
+	err = decodeRows(src, o, onHeader, dec.decode, func(r row, elmv reflect.Value) error {
+		if err := dec.unique(o.name, r, elmv); err != nil {
+			return err
+		}
+		if o.observer != nil {
+			o.observer.row(r, elmv, -1)
 		}
 		if elmIsPtr{
 			slicev.Set(reflect.Append(slicev, elmv.Addr()))
@@ -133,15 +160,30 @@ func ReadList(file string, isGbk bool, out interface{}) (err error) {
 }
 
 
-//ReadList read for map[interface{}]struct
-func ReadMap(file string, isGbk bool, keyField string, out interface{}) (err error) {
+//ReadMap read for map[interface{}]struct
+//keyField can be many fields split by comma, e.g. "heroId,level", for map[struct{...}]V, map[string]V or map[K1]map[K2]V
+func ReadMap(file string, isGbk bool, keyField string, out interface{}, opts ...Option) (err error) {
 	//catch panic
 	defer func() {
 		if rerr := recover(); rerr != nil {
 			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
 		}
 	}()
+	return readMap(fileSource(file), fileOptions(file, isGbk, opts), keyField, out)
+}
 
+//ReadMapContext read for map[interface{}]struct from r
+func ReadMapContext(ctx context.Context, r io.Reader, keyField string, out interface{}, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return readMap(streamSource(r), streamOptions(ctx, opts), keyField, out)
+}
+
+func readMap(src rowSource, o *options, keyField string, out interface{}) (err error) {
 	if out == nil {
 		return errors.New("Cannot remake from <nil>")
 	}
@@ -159,7 +201,7 @@ func ReadMap(file string, isGbk bool, keyField string, out interface{}) (err err
 	mapk := mapv.Kind()
 
 	if mapk != reflect.Map {
-		return errors.New("Pointer must point to a slice")
+		return errors.New("Pointer must point to a map")
 	}
 
 	//make map
@@ -167,50 +209,83 @@ func ReadMap(file string, isGbk bool, keyField string, out interface{}) (err err
 		mapv.Set(reflect.MakeMap(mapt))
 	}
 
-	elmt := mapt.Elem()
-	elmIsPtr := false
-	//element is ptr
-	if elmt.Kind() == reflect.Ptr{
-		elmt = elmt.Elem()
-		elmIsPtr = true
+	target, err := newMapTarget(mapv, keyField, o.keySep, o.duplicate)
+	if err != nil {
+		return err
 	}
 
-	//map field => value
-	idxs := make(map[string]int)
-	for i := 0; i < elmt.NumField(); i++ {
-		name := elmt.Field(i).Tag.Get("csv")
-		if len(name) <= 0 {
-			name = elmt.Field(i).Name
+	dec, err := newElemDecoder(target.elmt, o.mapper)
+	if err != nil {
+		return err
+	}
+	//primary key => struct field
+	keyIdxs := make([]int, len(target.keyFields))
+	for i, name := range target.keyFields {
+		idx, ok := dec.fieldIndex(name)
+		if !ok {
+			return errors.New(fmt.Sprintf("Primary key not found, %v not has field \"%v\"", target.elmt, name))
 		}
-		idxs[format(name)] = i
+		keyIdxs[i] = idx
 	}
-
-	err = ReadRaw(file, isGbk, func(fields []Field) error {
-		elmv := reflect.Indirect(reflect.New(elmt))
-		keyi := 0
-		isMatchKey := false
-		for _, f := range fields {
-			if len(f.Name) <= 0 {
-				continue
+	//line and observed index of each key
+	type keyRow struct {
+		line int
+		seq  int
+	}
+	keyRows := make(map[string]keyRow)
+	seq := 0
+	onHeader := func(h *header) error {
+		if err := dec.checkColumns(h, o); err != nil {
+			return err
+		}
+		for i, idx := range keyIdxs {
+			if !dec.hasColumn(h.names, idx) {
+				return errors.New(fmt.Sprintf("Primary key not found, \"%v\" not has field name \"%v\"",o.name, target.keyFields[i]))
 			}
-			idx, ok := idxs[format(f.Name)]
-			if !ok {
-				continue
+		}
+		return nil
+	}
+	err = decodeRows(src, o, onHeader, dec.decode, func(r row, elmv reflect.Value) error {
+		if err := dec.unique(o.name, r, elmv); err != nil {
+			return err
+		}
+		parts := make([]reflect.Value, len(keyIdxs))
+		for i, idx := range keyIdxs {
+			parts[i] = elmv.Field(idx)
+		}
+		keys, err := target.keys(parts)
+		if err != nil {
+			return err
+		}
+		m, old := target.get(keys)
+		//observer sees stored rows only
+		replace := -1
+		if target.group {
+			target.add(m, keys, old, elmv)
+		} else {
+			//only rows of this read are duplicates, entries already in the map are overwritten
+			key := keyString(keys, "\x00")
+			prev, seen := keyRows[key]
+			if seen {
+				switch o.duplicate {
+				case DuplicateFirst:
+					return nil
+				case DuplicateLast:
+					replace = prev.seq
+				default:
+					return readError(o.name, fmt.Sprintf("Duplicate primary key \"%v\" at line %v and line %v", keyString(keys, ","), prev.line, r.line))
+				}
 			}
-			if f.Name == keyField {
-				keyi = idx
-				isMatchKey = true
+			target.set(m, keys, elmv)
+			if replace < 0 {
+				keyRows[key] = keyRow{line: r.line, seq: seq}
+				seq++
+			} else {
+				keyRows[key] = keyRow{line: r.line, seq: replace}
 			}
-			fValue := elmv.Field(idx)
-			setValue(&fValue, f)
 		}
-		if !isMatchKey{
-			return errors.New(fmt.Sprintf("Primary key not found, \"%v\" not has field name \"%v\"",file, keyField))
-		}
-		if elmIsPtr{
-			mapv.SetMapIndex(elmv.Field(keyi), elmv.Addr())
-		}else{
-			mapv.SetMapIndex(elmv.Field(keyi), elmv)
+		if o.observer != nil {
+			o.observer.row(r, elmv, replace)
 		}
 		return nil
 	})
@@ -234,43 +309,147 @@ func ReadLines(file string, isGbk bool) (lines [][]string, err error) {
 		return nil, err
 	}
 	defer fi.Close()
-	//get reader
-	var reader *csv.Reader
-	if !isGbk {
-		reader = csv.NewReader(fi)
-	} else {
-		//transform gbk to utf8
-		r := transform.NewReader(fi, simplifiedchinese.GBK.NewDecoder())
-		reader = csv.NewReader(r)
-	}
-	lines, err = reader.ReadAll()
+	lines, err = newCsvReader(fi, isGbk).ReadAll()
 	return
 }
 
-func setValue(elmv *reflect.Value, f Field)  {
-	switch f.Kind {
-	case "int", "int64", "long":
-		itemValue, innerr := parseInt(f.Value)
-		if innerr != nil {
-			itemValue = 0
+//elemDecoder convert fields to struct
+type elemDecoder struct {
+	elmt    reflect.Type
+	tags    []fieldTag
+	idxs    map[string]int
+	rest    int //index of rest field, -1 if none
+	mapper  NameMapper
+	uniques map[int]map[string]int //field index => value => line
+}
+
+func newElemDecoder(elmt reflect.Type, mapper NameMapper) (*elemDecoder, error) {
+	if elmt.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf("Cannot read into %v, it must be struct", elmt))
+	}
+	//map field => value
+	idxs := make(map[string]int)
+	tags := make([]fieldTag, elmt.NumField())
+	rest := -1
+	uniques := make(map[int]map[string]int)
+	for i := 0; i < elmt.NumField(); i++ {
+		tag, err := parseTag(elmt.Field(i))
+		if err != nil {
+			return nil, err
 		}
-		elmv.SetInt(itemValue)
-	case "float", "float64", "double":
-		itemValue, innerr := parseFloat(f.Value)
-		if innerr != nil {
-			itemValue = 0
+		tags[i] = tag
+		if tag.unique {
+			uniques[i] = make(map[string]int)
 		}
-		elmv.SetFloat(itemValue)
-	case "bool":
-		itemValue, innerr := strconv.ParseBool(f.Value)
-		if innerr != nil {
-			itemValue = false
+		//set by TableSet
+		if tags[i].ref != "" {
+			continue
+		}
+		if tags[i].rest {
+			if rest >= 0 {
+				return nil, errors.New(fmt.Sprintf("%v has more than one rest field", elmt))
+			}
+			if t := elmt.Field(i).Type; t != restMapType && t != restListType {
+				return nil, errors.New(fmt.Sprintf("Rest field %v.%v must be map[string]string or []gocsv.Field, not %v", elmt, elmt.Field(i).Name, t))
+			}
+			rest = i
+			continue
+		}
+		idxs[mapper(tags[i].name)] = i
+	}
+	return &elemDecoder{elmt: elmt, tags: tags, idxs: idxs, rest: rest, mapper: mapper, uniques: uniques}, nil
+}
+
+//index struct field index of csv field name
+func (d *elemDecoder) index(name string) (int, bool) {
+	if len(name) <= 0 {
+		return 0, false
+	}
+	idx, ok := d.idxs[d.mapper(name)]
+	return idx, ok
+}
+
+//fieldIndex struct field index of key, key is csv field name or go field name,
+//it is matched case-insensitively if there is no exact match
+func (d *elemDecoder) fieldIndex(key string) (int, bool) {
+	if idx, ok := d.index(key); ok {
+		return idx, true
+	}
+	for i := 0; i < d.elmt.NumField(); i++ {
+		if d.elmt.Field(i).Name == key {
+			return i, true
+		}
+	}
+	found := -1
+	for i := 0; i < d.elmt.NumField(); i++ {
+		sf := d.elmt.Field(i)
+		if !strings.EqualFold(sf.Name, key) && !strings.EqualFold(d.tags[i].name, key) {
+			continue
+		}
+		//ambiguous
+		if found >= 0 {
+			return 0, false
+		}
+		found = i
+	}
+	return found, found >= 0
+}
+
+//hasColumn struct field idx is set by one of csv field names
+func (d *elemDecoder) hasColumn(names []string, idx int) bool {
+	for _, name := range names {
+		if i, ok := d.index(name); ok && i == idx {
+			return true
 		}
-		elmv.SetBool(itemValue)
-	default:
-		itemValue := f.Value
-		elmv.SetString(itemValue)
 	}
+	return false
+}
+
+//decode new struct value from fields of row and validate it, it is safe for concurrent use
+func (d *elemDecoder) decode(r row) (elmv reflect.Value, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("%v", rerr))
+		}
+	}()
+	elmv = reflect.Indirect(reflect.New(d.elmt))
+	values := make(map[int]string)
+	for _, f := range r.fields {
+		idx, ok := d.index(f.Name)
+		if !ok {
+			if d.rest >= 0 && len(f.Name) > 0 {
+				addRest(elmv.Field(d.rest), f)
+			}
+			continue
+		}
+		fValue := elmv.Field(idx)
+		setValue(&fValue, f)
+		values[idx] = f.Value
+	}
+	return elmv, d.validate(r, values, elmv)
+}
+
+func setValue(elmv *reflect.Value, f Field)  {
+	//kind of field type, e.g. kind is "ref:category.id" or the field is string of kind "[]int"
+	if k, err := LookupKind(f.Kind); err == nil && setKind(elmv, k, f.Value) {
+		return
+	}
+	kind, ok := kindOf(elmv.Type())
+	if !ok {
+		panic(fmt.Sprintf("Cannot set field of %v by kind \"%v\"", elmv.Type(), f.Kind))
+	}
+	k, _ := LookupKind(kind)
+	setKind(elmv, k, f.Value)
+}
+
+//setKind set value of kind, invalid value is zero, it is false if the field is not of kind
+func setKind(elmv *reflect.Value, k *Kind, value string) bool {
+	itemValue, innerr := k.Parse(value)
+	if innerr != nil {
+		itemValue = reflect.Zero(k.Type).Interface()
+	}
+	return assign(*elmv, reflect.ValueOf(itemValue))
 }
 
 func parseInt(val string) (int64, error)  {
@@ -301,51 +480,33 @@ func parseFloat(val string) (float64, error)  {
 	return eval, err
 }
 
-//Read read csv for handle
-func ReadRaw(file string, isGbk bool, handle func([]Field) error) (err error) {
+//ReadRaw read csv for handle
+func ReadRaw(file string, isGbk bool, handle func([]Field) error, opts ...Option) (err error) {
 	//catch panic
 	defer func() {
 		if rerr := recover(); rerr != nil {
 			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
 		}
 	}()
-	if file == ""{
-		return errors.New("read csv file parameter is empty.")
-	}
-	lines, err := ReadLines(file, isGbk)
-	if err != nil {
-		return err
-	}
-	lineNum := len(lines)
-	if (lineNum < 3) {
-		return errors.New(fmt.Sprintf("Csv %v is invalid"))
-	}
-	names, kinds := lines[1], lines[2]
-	fieldNum := len(names)
-	//从第三行开始
-	for i := 3; i < lineNum; i++ {
-		line := lines[i]
-		itemFields := make([]Field, fieldNum, fieldNum)
-		for j := 0; j < fieldNum; j++ {
-			itemField := Field{
-				Name: trim(names[j]),
-				Value: trim(line[j]),
-				Kind: trim(kinds[j]),
+	return fileSource(file)(fileOptions(file, isGbk, opts), nil, fieldsHandle(handle))
+}
 
-			}
-			itemFields[j] = itemField
-		}
-		perr := handle(itemFields)
-		//如果返回解析错误，则跳过，直接返回
-		if perr != nil {
-			return perr
+//ReadRawContext read csv from r for handle
+func ReadRawContext(ctx context.Context, r io.Reader, handle func([]Field) error, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
 		}
-	}
-	return nil
+	}()
+	return streamSource(r)(streamOptions(ctx, opts), nil, fieldsHandle(handle))
 }
 
 //format format name
 func format(name string) string {
+	if name == "" {
+		return name
+	}
 	return fmt.Sprintf("%v%v", strings.ToLower(name[0:1]), name[1:])
 }
 
@@ -0,0 +1,32 @@
+package gocsv
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const typedCsv = "a,b,c,d,e\nid,rate,ok,tags,hp\nint,double,bool,[]int,long\n1,0.5,true,1|2,7\n"
+
+func TestReadValues(t *testing.T) {
+	list, err := ReadContext(context.Background(), strings.NewReader(typedCsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": int64(1), "rate": "0.5", "ok": "true", "tags": "1|2", "hp": "7"}
+	if len(list) != 1 || !reflect.DeepEqual(list[0], want) {
+		t.Fatalf("list: %#v, want: %#v", list, want)
+	}
+}
+
+func TestReadTypedValues(t *testing.T) {
+	list, err := ReadContext(context.Background(), strings.NewReader(typedCsv), WithTypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": int64(1), "rate": 0.5, "ok": true, "tags": []int{1, 2}, "hp": int64(7)}
+	if len(list) != 1 || !reflect.DeepEqual(list[0], want) {
+		t.Fatalf("list: %#v, want: %#v", list, want)
+	}
+}
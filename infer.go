@@ -0,0 +1,224 @@
+package gocsv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+//inferKinds kinds proposed by InferSchema in order, kind of column is the first one matched by all its values
+var inferKinds = []string{"bool", "int", "int64", "float", "time"}
+
+//guess kinds matched by values of a column
+type guess struct {
+	values int    //non-empty values
+	array  bool   //some value has ArraySep
+	scalar []bool //value matches inferKinds[i]
+	elem   []bool //every element of value matches inferKinds[i]
+}
+
+func newGuess() *guess {
+	g := &guess{scalar: make([]bool, len(inferKinds)), elem: make([]bool, len(inferKinds))}
+	for i := range inferKinds {
+		g.scalar[i], g.elem[i] = true, true
+	}
+	return g
+}
+
+func (g *guess) add(value string) {
+	if value == "" {
+		return
+	}
+	g.values++
+	if strings.Contains(value, ArraySep) {
+		g.array = true
+	}
+	for i, kind := range inferKinds {
+		g.scalar[i] = g.scalar[i] && inferMatch(kind, value)
+		for _, elem := range strings.Split(value, ArraySep) {
+			g.elem[i] = g.elem[i] && inferMatch(kind, trim(elem))
+		}
+	}
+}
+
+//kind proposed kind, values with ArraySep are array of kind matched by all elements or array of string
+func (g *guess) kind() string {
+	if g.values == 0 {
+		return "string"
+	}
+	matched := g.scalar
+	if g.array {
+		matched = g.elem
+	}
+	for i, kind := range inferKinds {
+		if !matched[i] {
+			continue
+		}
+		if g.array {
+			return "[]" + kind
+		}
+		return kind
+	}
+	if g.array {
+		return "[]string"
+	}
+	return "string"
+}
+
+//inferMatch value is of kind, numbers with leading zeros are codes of string, e.g. "007",
+//and words like "Inf" or "NaN" are not floats
+func inferMatch(kind string, value string) bool {
+	switch kind {
+	case "bool":
+		switch value {
+		case "true", "false", "TRUE", "FALSE", "True", "False":
+			return true
+		}
+		return false
+	case "int":
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err == nil && !leadingZero(value)
+	case "int64":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && !leadingZero(value)
+	case "float":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil && !leadingZero(value) && strings.IndexFunc(value, func(c rune) bool {
+			return unicode.IsLetter(c) && c != 'e' && c != 'E'
+		}) < 0
+	case "time":
+		_, err := parseTime(value)
+		return err == nil
+	}
+	return false
+}
+
+//leadingZero number has zero before other digits, e.g. "007" or "-01.5"
+func leadingZero(value string) bool {
+	value = strings.TrimLeft(value, "+-")
+	return len(value) > 1 && value[0] == '0' && value[1] >= '0' && value[1] <= '9'
+}
+
+//InferSchema read rows of csv file and propose kind of each column by its values, for csv without kind row,
+//e.g. csv of third party, the csv has NameLayout unless WithLayout is given and constraints of its kind row are kept.
+//kind is the first of bool, int, int64, float and time matched by all values, or string,
+//it is array of them if values have ArraySep
+func InferSchema(file string, isGbk bool, opts ...Option) (cols []Column, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
+		}
+	}()
+	return inferSchema(fileSource(file), fileOptions(file, isGbk, inferOptions(opts)))
+}
+
+//InferSchemaContext read rows of csv from r and propose kind of each column
+func InferSchemaContext(ctx context.Context, r io.Reader, opts ...Option) (cols []Column, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return inferSchema(streamSource(r), streamOptions(ctx, inferOptions(opts)))
+}
+
+//inferOptions opts with NameLayout by default
+func inferOptions(opts []Option) []Option {
+	return append([]Option{WithLayout(NameLayout)}, opts...)
+}
+
+func inferSchema(src rowSource, o *options) ([]Column, error) {
+	var cols []Column
+	var guesses []*guess
+	err := src(o, func(h *header) error {
+		cols = h.columns()
+		for range cols {
+			guesses = append(guesses, newGuess())
+		}
+		return nil
+	}, func(r row) error {
+		for i, f := range r.fields {
+			guesses[i].add(f.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range cols {
+		cols[i].Kind = guesses[i].kind()
+	}
+	return cols, nil
+}
+
+//kindRow value of column in kind row, e.g. "int(1..100)! unique"
+func (c Column) kindRow() string {
+	kind := c.Kind
+	if c.Range != "" {
+		kind = kind + "(" + c.Range + ")"
+	}
+	if c.Required {
+		kind = kind + "!"
+	}
+	tokens := []string{kind}
+	if c.Unique {
+		tokens = append(tokens, "unique")
+	}
+	if c.Ref != "" {
+		tokens = append(tokens, "ref:"+c.Ref)
+	}
+	return strings.TrimSpace(strings.Join(tokens, " "))
+}
+
+//WriteHeader write header rows of cols to csv file, e.g. template of columns proposed by InferSchema
+func WriteHeader(file string, isGbk bool, cols []Column) error {
+	fi, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer fi.Close()
+	if err := WriteHeaderTo(fi, isGbk, cols); err != nil {
+		return err
+	}
+	return fi.Close()
+}
+
+//WriteHeaderTo write header rows of description, field name and kind of cols to w,
+//empty description is field name, target row is written after kind row if any column has target
+func WriteHeaderTo(w io.Writer, isGbk bool, cols []Column) error {
+	descs, names, kinds, targets := make([]string, len(cols)), make([]string, len(cols)), make([]string, len(cols)), make([]string, len(cols))
+	hasTarget := false
+	for i, c := range cols {
+		descs[i], names[i], kinds[i], targets[i] = c.Desc, c.Name, c.kindRow(), c.Target
+		if descs[i] == "" {
+			descs[i] = c.Name
+		}
+		hasTarget = hasTarget || c.Target != ""
+	}
+	lines := [][]string{descs, names, kinds}
+	if hasTarget {
+		lines = append(lines, targets)
+	}
+
+	if isGbk {
+		tw := transform.NewWriter(w, simplifiedchinese.GBK.NewEncoder())
+		defer tw.Close()
+		w = tw
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(lines); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,302 @@
+package gocsv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ArraySep separator of array values, e.g. "1|2|3" of kind "[]int"
+const ArraySep = "|"
+
+//MapSep separator of key and value of map, e.g. "a:1|b:2" of kind "map[string]int"
+const MapSep = ":"
+
+//Kind kind of column, it is declared in kind row
+type Kind struct {
+	Name   string       //name in kind row, e.g. "int" or "[]int"
+	Type   reflect.Type //go type of values, generated fields have this type
+	Parse  func(value string) (interface{}, error)
+	Values []string //values of enum kind, e.g. "enum(a|b)"
+}
+
+//TimeLayouts layouts of time kind, value is parsed by them in order, time without zone is UTC
+var TimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02", "2006/01/02 15:04:05", "2006/01/02"}
+
+//timeType go type of time kind
+var timeType = reflect.TypeOf(time.Time{})
+
+//kinds kinds by name, aliases share the same go type
+var kinds = map[string]*Kind{}
+
+//madeKinds array and enum kinds made by LookupKind
+var madeKinds sync.Map
+
+//kindNames names of kinds, the first kind of a go type is written by WriteList
+var kindNames = []string{"int", "int64", "float", "float32", "bool", "string", "time", "long", "double", "float64"}
+
+func init() {
+	addKind("int", reflect.TypeOf(0), func(value string) (interface{}, error) {
+		v, err := parseInt(value)
+		return int(v), err
+	})
+	addKind("int64", reflect.TypeOf(int64(0)), func(value string) (interface{}, error) {
+		return parseInt(value)
+	})
+	addKind("float", reflect.TypeOf(float64(0)), func(value string) (interface{}, error) {
+		return parseFloat(value)
+	})
+	addKind("float32", reflect.TypeOf(float32(0)), func(value string) (interface{}, error) {
+		v, err := parseFloat(value)
+		return float32(v), err
+	})
+	addKind("bool", reflect.TypeOf(false), func(value string) (interface{}, error) {
+		return strconv.ParseBool(value)
+	})
+	addKind("string", reflect.TypeOf(""), func(value string) (interface{}, error) {
+		return value, nil
+	})
+	addKind("time", timeType, func(value string) (interface{}, error) {
+		return parseTime(value)
+	})
+	kinds["long"] = &Kind{Name: "long", Type: kinds["int64"].Type, Parse: kinds["int64"].Parse}
+	kinds["double"] = &Kind{Name: "double", Type: kinds["float"].Type, Parse: kinds["float"].Parse}
+	kinds["float64"] = &Kind{Name: "float64", Type: kinds["float"].Type, Parse: kinds["float"].Parse}
+}
+
+func addKind(name string, t reflect.Type, parse func(value string) (interface{}, error)) {
+	kinds[name] = &Kind{Name: name, Type: t, Parse: parse}
+}
+
+//parseTime parse value by TimeLayouts
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range TimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New(fmt.Sprintf("Time \"%v\" does not match layouts: %v", value, strings.Join(TimeLayouts, ", ")))
+}
+
+//formatTime value of time kind, zero time is empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+//LookupKind kind of name, array kind is "[]" and kind of element, e.g. "[]int" of "1|2|3",
+//map kind is "map[key]value" of scalar kinds, e.g. "map[string]int" of "a:1|b:2",
+//enum kind is string of values, e.g. "enum(red|green)"
+func LookupKind(name string) (*Kind, error) {
+	if k, ok := kinds[name]; ok {
+		return k, nil
+	}
+	if k, ok := madeKinds.Load(name); ok {
+		return k.(*Kind), nil
+	}
+	var k *Kind
+	switch {
+	case strings.HasPrefix(name, "[]"):
+		elem, err := LookupKind(name[2:])
+		if err != nil {
+			return nil, err
+		}
+		k = arrayKind(name, elem)
+	case strings.HasPrefix(name, "map[") && strings.Contains(name, "]"):
+		var err error
+		if k, err = mapKind(name); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(name, "enum(") && strings.HasSuffix(name, ")"):
+		var err error
+		if k, err = enumKind(name); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Kind \"%v\" is unknown, it must be one of %v, enum(a|b) or array of them, e.g. []int", name, strings.Join(kindNames, ", ")))
+	}
+	madeKinds.Store(name, k)
+	return k, nil
+}
+
+//mapKind kind of "map[key]value", entries are separated by ArraySep, empty value is empty map
+func mapKind(name string) (*Kind, error) {
+	i := strings.Index(name, "]")
+	key, err := LookupKind(name[len("map["):i])
+	if err != nil {
+		return nil, err
+	}
+	elem, err := LookupKind(name[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range []*Kind{key, elem} {
+		if k.Type.Kind() == reflect.Slice || k.Type.Kind() == reflect.Map {
+			return nil, errors.New(fmt.Sprintf("Kind \"%v\" must have scalar key and value", name))
+		}
+	}
+	//json has keys of string and int only
+	if !isInt(key.Type.Kind()) && key.Type.Kind() != reflect.String {
+		return nil, errors.New(fmt.Sprintf("Kind \"%v\" must have key of int or string", name))
+	}
+	t := reflect.MapOf(key.Type, elem.Type)
+	return &Kind{Name: name, Type: t, Parse: func(value string) (interface{}, error) {
+		mapv := reflect.MakeMap(t)
+		if trim(value) == "" {
+			return mapv.Interface(), nil
+		}
+		for _, entry := range strings.Split(value, ArraySep) {
+			kv := strings.SplitN(entry, MapSep, 2)
+			if len(kv) != 2 {
+				return nil, errors.New(fmt.Sprintf("Entry \"%v\" of %v has no \"%v\"", entry, name, MapSep))
+			}
+			k, err := key.Parse(trim(kv[0]))
+			if err != nil {
+				return nil, err
+			}
+			v, err := elem.Parse(trim(kv[1]))
+			if err != nil {
+				return nil, err
+			}
+			if mapv.MapIndex(reflect.ValueOf(k)).IsValid() {
+				return nil, errors.New(fmt.Sprintf("Entry \"%v\" of %v has duplicate key", entry, name))
+			}
+			mapv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v))
+		}
+		return mapv.Interface(), nil
+	}}, nil
+}
+
+//enumKind string kind of values, e.g. "enum(red|green)", empty value is allowed
+func enumKind(name string) (*Kind, error) {
+	values := strings.Split(name[len("enum("):len(name)-1], "|")
+	for i := range values {
+		values[i] = trim(values[i])
+		if values[i] == "" {
+			return nil, errors.New(fmt.Sprintf("Kind \"%v\" has empty enum value", name))
+		}
+	}
+	return &Kind{Name: name, Type: kinds["string"].Type, Values: values, Parse: func(value string) (interface{}, error) {
+		if value == "" {
+			return value, nil
+		}
+		for _, v := range values {
+			if v == value {
+				return value, nil
+			}
+		}
+		return nil, errors.New(fmt.Sprintf("Value \"%v\" is not one of %v", value, name))
+	}}, nil
+}
+
+//arrayKind kind of values separated by ArraySep, empty value is empty array
+func arrayKind(name string, elem *Kind) *Kind {
+	t := reflect.SliceOf(elem.Type)
+	return &Kind{Name: name, Type: t, Parse: func(value string) (interface{}, error) {
+		slicev := reflect.MakeSlice(t, 0, 0)
+		if trim(value) == "" {
+			return slicev.Interface(), nil
+		}
+		for _, s := range strings.Split(value, ArraySep) {
+			v, err := elem.Parse(trim(s))
+			if err != nil {
+				return nil, err
+			}
+			slicev = reflect.Append(slicev, reflect.ValueOf(v))
+		}
+		return slicev.Interface(), nil
+	}}
+}
+
+//kindOf csv kind of go type
+func kindOf(t reflect.Type) (string, bool) {
+	for _, name := range kindNames {
+		if kinds[name].Type == t {
+			return name, true
+		}
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int", true
+	case reflect.Float32, reflect.Float64:
+		return "float", true
+	case reflect.Bool:
+		return "bool", true
+	case reflect.String:
+		return "string", true
+	case reflect.Slice:
+		if elem, ok := kindOf(t.Elem()); ok && t.Elem().Kind() != reflect.Slice && t.Elem().Kind() != reflect.Map {
+			return "[]" + elem, true
+		}
+	case reflect.Map:
+		key, ok := kindOf(t.Key())
+		elem, eok := kindOf(t.Elem())
+		if ok && eok && !strings.ContainsAny(key+elem, "[") {
+			return "map[" + key + "]" + elem, true
+		}
+	}
+	return "", false
+}
+
+//isNumber kind of int or float values
+func isNumber(kind string) bool {
+	k, err := LookupKind(kind)
+	if err != nil {
+		return false
+	}
+	return isInt(k.Type.Kind()) || isFloat(k.Type.Kind())
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+//assign set parsed value v to field, it is false if types are incompatible
+func assign(field reflect.Value, v reflect.Value) bool {
+	switch {
+	case isInt(field.Kind()) && isInt(v.Kind()):
+		field.SetInt(v.Int())
+	case isFloat(field.Kind()) && isFloat(v.Kind()):
+		field.SetFloat(v.Float())
+	case isFloat(field.Kind()) && isInt(v.Kind()):
+		field.SetFloat(float64(v.Int()))
+	case field.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		field.SetBool(v.Bool())
+	case field.Kind() == reflect.String && v.Kind() == reflect.String:
+		field.SetString(v.String())
+	case field.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
+		slicev := reflect.MakeSlice(field.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !assign(slicev.Index(i), v.Index(i)) {
+				return false
+			}
+		}
+		field.Set(slicev)
+	case field.Kind() == reflect.Map && v.Kind() == reflect.Map:
+		mapv := reflect.MakeMapWithSize(field.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, elem := reflect.New(field.Type().Key()).Elem(), reflect.New(field.Type().Elem()).Elem()
+			if !assign(key, iter.Key()) || !assign(elem, iter.Value()) {
+				return false
+			}
+			mapv.SetMapIndex(key, elem)
+		}
+		field.Set(mapv)
+	case field.Type() == v.Type():
+		field.Set(v)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package gocsv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Layout row index of header rows, -1 if the row is absent
+type Layout struct {
+	Desc   int //description row
+	Name   int //field name row, it is required
+	Kind   int //kind row, absent kind is string
+	Target int //export target row, values are c, s or cs, empty is both
+}
+
+//DefaultLayout description, field name and kind rows
+var DefaultLayout = Layout{Desc: 0, Name: 1, Kind: 2, Target: -1}
+
+//TargetLayout DefaultLayout with export target row after kind row
+var TargetLayout = Layout{Desc: 0, Name: 1, Kind: 2, Target: 3}
+
+//NameLayout field name row only, e.g. csv of third party, kinds of it are proposed by InferSchema
+var NameLayout = Layout{Desc: -1, Name: 0, Kind: -1, Target: -1}
+
+//rows number of header rows
+func (l Layout) rows() int {
+	n := 0
+	for _, i := range []int{l.Desc, l.Name, l.Kind, l.Target} {
+		if i+1 > n {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+//WithLayout header rows of csv
+func WithLayout(l Layout) Option {
+	return func(o *options) {
+		o.layout = l
+	}
+}
+
+//Target export target of columns
+type Target string
+
+const (
+	//TargetAll all columns
+	TargetAll Target = ""
+	//TargetClient columns marked c or cs
+	TargetClient Target = "c"
+	//TargetServer columns marked s or cs
+	TargetServer Target = "s"
+)
+
+//ParseTarget parse "client", "server", "c", "s", "all" or ""
+func ParseTarget(s string) (Target, error) {
+	switch strings.ToLower(trim(s)) {
+	case "", "all", "cs":
+		return TargetAll, nil
+	case "c", "client":
+		return TargetClient, nil
+	case "s", "server":
+		return TargetServer, nil
+	}
+	return TargetAll, errors.New(fmt.Sprintf("Target \"%v\" is invalid, it must be client, server or all", s))
+}
+
+//Match column of mark is exported to t, empty mark is both
+func (t Target) Match(mark string) bool {
+	mark = strings.ToLower(trim(mark))
+	if t == TargetAll || mark == "" {
+		return true
+	}
+	return strings.Contains(mark, string(t))
+}
+
+//WithTarget only read columns of target, the target row is set by WithLayout
+func WithTarget(t Target) Option {
+	return func(o *options) {
+		o.target = t
+	}
+}
+
+//TargetOptions options of -target flag of tools, empty target is nil options of DefaultLayout,
+//others are TargetLayout and WithTarget of ParseTarget(target)
+func TargetOptions(target string) ([]Option, error) {
+	if target == "" {
+		return nil, nil
+	}
+	t, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	return []Option{WithLayout(TargetLayout), WithTarget(t)}, nil
+}
+
+//Column column of csv header
+type Column struct {
+	Desc     string
+	Name     string
+	Kind     string //kind without constraints
+	Target   string
+	Required bool   //kind is "kind!" or "kind required"
+	Unique   bool   //kind is "kind unique"
+	Range    string //kind is "kind(min..max)", range is "min..max"
+	Ref      string //kind is "ref:table.column" or "kind ref:table.column", ref is "table.column"
+}
+
+//columns of header
+func (h *header) columns() []Column {
+	cols := make([]Column, len(h.names))
+	for i := range cols {
+		c := h.constraints[i]
+		cols[i] = Column{Desc: h.descs[i], Name: h.names[i], Kind: h.kinds[i], Target: h.targets[i], Required: c.required, Unique: c.unique, Range: c.rng, Ref: c.ref}
+	}
+	return cols
+}
+
+//errHeaderDone stop reading after header
+var errHeaderDone = errors.New("header done")
+
+//ReadHeader read columns of csv file, options of layout, target and comment columns are applied
+func ReadHeader(file string, isGbk bool, opts ...Option) (cols []Column, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
+		}
+	}()
+	return readHeader(fileSource(file), fileOptions(file, isGbk, opts))
+}
+
+//ReadHeaderContext read columns of csv from r
+func ReadHeaderContext(ctx context.Context, r io.Reader, opts ...Option) (cols []Column, err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return readHeader(streamSource(r), streamOptions(ctx, opts))
+}
+
+func readHeader(src rowSource, o *options) ([]Column, error) {
+	var cols []Column
+	err := src(o, func(h *header) error {
+		cols = h.columns()
+		return errHeaderDone
+	}, nil)
+	if err != nil && err != errHeaderDone {
+		return nil, err
+	}
+	return cols, nil
+}
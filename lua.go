@@ -0,0 +1,219 @@
+package gocsv
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//luaKeywords keywords of lua, they are not field names
+var luaKeywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields("and break do else elseif end false for function goto if in local nil not or repeat return then true until while") {
+		luaKeywords[k] = true
+	}
+}
+
+//WriteLua write csv file to w as lua module, it returns table of rows keyed by keyField,
+//empty keyField is the first unique column or the first column.
+//values are typed by kind row, e.g. arrays are sequences and maps are tables, empty value is zero value of kind
+func WriteLua(file string, isGbk bool, keyField string, w io.Writer, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
+		}
+	}()
+	return writeLua(fileSource(file), fileOptions(file, isGbk, opts), keyField, w)
+}
+
+//WriteLuaContext write csv from r to w as lua module
+func WriteLuaContext(ctx context.Context, r io.Reader, keyField string, w io.Writer, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return writeLua(streamSource(r), streamOptions(ctx, opts), keyField, w)
+}
+
+//WriteLuaFile write csv file to lua file, out is kept if csv is invalid
+func WriteLuaFile(file string, isGbk bool, keyField string, out string, opts ...Option) error {
+	return writeOutput(out, func(w io.Writer) error {
+		return WriteLua(file, isGbk, keyField, w, opts...)
+	})
+}
+
+func writeLua(src rowSource, o *options, keyField string, w io.Writer) error {
+	var h *header
+	kinds := make([]*Kind, 0)
+	key := -1
+	keyLines := make(map[string]int)
+	bw := bufio.NewWriter(w)
+	err := src(o, func(hd *header) error {
+		h = hd
+		for i, name := range h.names {
+			kind := h.kinds[i]
+			if kind == "" {
+				kind = "string"
+			}
+			k, err := LookupKind(kind)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Column \"%v\": %v", name, err))
+			}
+			kinds = append(kinds, k)
+			if key < 0 && (name == keyField || keyField == "" && h.constraints[i].unique) {
+				key = i
+			}
+		}
+		if key < 0 && keyField == "" && len(h.names) > 0 {
+			key = 0
+		}
+		if key < 0 {
+			return errors.New(fmt.Sprintf("Csv has no key column \"%v\"", keyField))
+		}
+		if kinds[key].Type.Kind() == reflect.Slice || kinds[key].Type.Kind() == reflect.Map {
+			return errors.New(fmt.Sprintf("Key column \"%v\" of kind \"%v\" cannot be key of lua table", h.names[key], kinds[key].Name))
+		}
+		_, err := bw.WriteString("-- Code generated by github.com/foolin/gocsv. DO NOT EDIT.\nreturn {\n")
+		return err
+	}, func(r row) error {
+		values := make([]reflect.Value, len(r.fields))
+		for i, f := range r.fields {
+			v, err := kinds[i].Parse(f.Value)
+			if f.Value == "" && err != nil {
+				v, err = reflect.Zero(kinds[i].Type).Interface(), nil
+			}
+			if err != nil {
+				return &ValidationError{Name: o.name, Line: r.line, Column: h.names[i], Value: f.Value, Rule: kinds[i].Name}
+			}
+			values[i] = reflect.ValueOf(v)
+		}
+		//"01" and "1" of int are the same key
+		keyValue := luaValue(values[key])
+		if line, ok := keyLines[keyValue]; ok {
+			return readError(o.name, fmt.Sprintf("Duplicate primary key %v at line %v and line %v", keyValue, line, r.line))
+		}
+		keyLines[keyValue] = r.line
+
+		bw.WriteString("  [" + keyValue + "] = {")
+		for i, v := range values {
+			if i > 0 {
+				bw.WriteString(",")
+			}
+			bw.WriteString(" " + luaKey(h.names[i]) + " = " + luaValue(v))
+		}
+		_, err := bw.WriteString(" },\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return readError(o.name, "Csv has no header")
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+//luaKey field name, it is ["name"] if name is not an identifier
+func luaKey(name string) string {
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || i > 0 && c >= '0' && c <= '9') {
+			return "[" + luaString(name) + "]"
+		}
+	}
+	if name == "" || luaKeywords[name] {
+		return "[" + luaString(name) + "]"
+	}
+	return name
+}
+
+//luaValue lua literal of value, map keys are sorted
+func luaValue(v reflect.Value) string {
+	if v.Type() == timeType {
+		return luaString(formatTime(v.Interface().(time.Time)))
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return "0/0"
+		case math.IsInf(f, 1):
+			return "math.huge"
+		case math.IsInf(f, -1):
+			return "-math.huge"
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.String:
+		return luaString(v.String())
+	case reflect.Slice:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = luaValue(v.Index(i))
+		}
+		return "{" + strings.Join(values, ", ") + "}"
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Kind() == reflect.String {
+				return keys[i].String() < keys[j].String()
+			}
+			return keys[i].Int() < keys[j].Int()
+		})
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			if k.Kind() == reflect.String {
+				values[i] = luaKey(k.String()) + " = " + luaValue(v.MapIndex(k))
+			} else {
+				values[i] = "[" + luaValue(k) + "] = " + luaValue(v.MapIndex(k))
+			}
+		}
+		return "{" + strings.Join(values, ", ") + "}"
+	}
+	return luaString(fmt.Sprint(v.Interface()))
+}
+
+//luaString quoted lua string, control characters are escaped in decimal
+func luaString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				//\ddd with 3 digits, so a following digit is not part of it
+				fmt.Fprintf(&b, "\\%03d", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
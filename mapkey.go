@@ -0,0 +1,175 @@
+package gocsv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//mapTarget map[K]V, map[K1]map[K2]V ... with key fields
+type mapTarget struct {
+	mapv      reflect.Value
+	keyFields []string
+	keyts     []reflect.Type //key type of each level
+	elmt      reflect.Type
+	elmIsPtr  bool
+	group     bool //map[K][]V
+	sep       string
+}
+
+//newMapTarget parse map type, keyField is one field name or names split by comma, e.g. "heroId,level".
+//many key fields are for map[struct{...}]V, map[string]V (values joined by sep) or nested map[K1]map[K2]V.
+//map[K][]V collects rows with same key.
+func newMapTarget(mapv reflect.Value, keyField string, sep string, duplicate DuplicatePolicy) (*mapTarget, error) {
+	t := &mapTarget{mapv: mapv, sep: sep}
+	for _, name := range strings.Split(keyField, ",") {
+		name = trim(name)
+		if name == "" {
+			return nil, errors.New(fmt.Sprintf("Primary key \"%v\" is invalid", keyField))
+		}
+		t.keyFields = append(t.keyFields, name)
+	}
+
+	mapt := mapv.Type()
+	for mapt.Elem().Kind() == reflect.Map {
+		t.keyts = append(t.keyts, mapt.Key())
+		mapt = mapt.Elem()
+	}
+	t.keyts = append(t.keyts, mapt.Key())
+
+	t.elmt = mapt.Elem()
+	//element is slice
+	if t.elmt.Kind() == reflect.Slice {
+		t.elmt = t.elmt.Elem()
+		t.group = true
+	}
+	if duplicate == DuplicateGroup && !t.group {
+		return nil, errors.New(fmt.Sprintf("DuplicateGroup needs map element of slice, not %v", mapt.Elem()))
+	}
+	//element is ptr
+	if t.elmt.Kind() == reflect.Ptr {
+		t.elmt = t.elmt.Elem()
+		t.elmIsPtr = true
+	}
+	if t.elmt.Kind() != reflect.Struct {
+		return nil, errors.New("Map element must be struct, pointer of struct or slice of them")
+	}
+
+	if len(t.keyts) > 1 && len(t.keyts) != len(t.keyFields) {
+		return nil, errors.New(fmt.Sprintf("Nested map has %v levels, but primary key \"%v\" has %v fields", len(t.keyts), keyField, len(t.keyFields)))
+	}
+	if len(t.keyts) == 1 && len(t.keyFields) > 1 {
+		keyt := t.keyts[0]
+		switch {
+		case keyt.Kind() == reflect.String:
+		case keyt.Kind() == reflect.Struct && keyt.NumField() == len(t.keyFields):
+		case keyt.Kind() == reflect.Struct:
+			return nil, errors.New(fmt.Sprintf("Map key %v has %v fields, but primary key \"%v\" has %v fields", keyt, keyt.NumField(), keyField, len(t.keyFields)))
+		default:
+			return nil, errors.New(fmt.Sprintf("Map key of primary key \"%v\" must be struct or string, not %v", keyField, keyt))
+		}
+	}
+	return t, nil
+}
+
+//keys map keys of each level from key field values
+func (t *mapTarget) keys(parts []reflect.Value) ([]reflect.Value, error) {
+	if len(t.keyts) > 1 {
+		keys := make([]reflect.Value, len(parts))
+		for i, part := range parts {
+			key, err := convertKey(part, t.keyts[i])
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = key
+		}
+		return keys, nil
+	}
+
+	keyt := t.keyts[0]
+	if len(parts) == 1 {
+		key, err := convertKey(parts[0], keyt)
+		if err != nil {
+			return nil, err
+		}
+		return []reflect.Value{key}, nil
+	}
+	//tuple string
+	if keyt.Kind() == reflect.String {
+		strs := make([]string, len(parts))
+		for i, part := range parts {
+			strs[i] = fmt.Sprint(part.Interface())
+		}
+		return []reflect.Value{reflect.ValueOf(strings.Join(strs, t.sep)).Convert(keyt)}, nil
+	}
+	//struct key
+	key := reflect.New(keyt).Elem()
+	for i, part := range parts {
+		v, err := convertKey(part, keyt.Field(i).Type)
+		if err != nil {
+			return nil, err
+		}
+		key.Field(i).Set(v)
+	}
+	return []reflect.Value{key}, nil
+}
+
+//get value of keys, create nested maps if needed
+func (t *mapTarget) get(keys []reflect.Value) (reflect.Value, reflect.Value) {
+	m := t.mapv
+	for i := 0; i < len(keys)-1; i++ {
+		sub := m.MapIndex(keys[i])
+		if !sub.IsValid() {
+			sub = reflect.MakeMap(m.Type().Elem())
+			m.SetMapIndex(keys[i], sub)
+		}
+		m = sub
+	}
+	return m, m.MapIndex(keys[len(keys)-1])
+}
+
+//set elmv to keys
+func (t *mapTarget) set(m reflect.Value, keys []reflect.Value, elmv reflect.Value) {
+	if t.elmIsPtr {
+		m.SetMapIndex(keys[len(keys)-1], elmv.Addr())
+	} else {
+		m.SetMapIndex(keys[len(keys)-1], elmv)
+	}
+}
+
+//add elmv to slice of keys
+func (t *mapTarget) add(m reflect.Value, keys []reflect.Value, old reflect.Value, elmv reflect.Value) {
+	if !old.IsValid() {
+		old = reflect.MakeSlice(m.Type().Elem(), 0, 1)
+	}
+	if t.elmIsPtr {
+		m.SetMapIndex(keys[len(keys)-1], reflect.Append(old, elmv.Addr()))
+	} else {
+		m.SetMapIndex(keys[len(keys)-1], reflect.Append(old, elmv))
+	}
+}
+
+//keyString keys joined by sep
+func keyString(keys []reflect.Value, sep string) string {
+	strs := make([]string, len(keys))
+	for i, key := range keys {
+		strs[i] = fmt.Sprintf("%v", key.Interface())
+	}
+	return strings.Join(strs, sep)
+}
+
+//convertKey convert field value to key type
+func convertKey(v reflect.Value, keyt reflect.Type) (reflect.Value, error) {
+	if v.Type().AssignableTo(keyt) {
+		return v, nil
+	}
+	//int => string is not rune
+	if keyt.Kind() == reflect.String {
+		return reflect.ValueOf(fmt.Sprint(v.Interface())).Convert(keyt), nil
+	}
+	if v.Kind() != reflect.String && v.Type().ConvertibleTo(keyt) {
+		return v.Convert(keyt), nil
+	}
+	return reflect.Value{}, errors.New(fmt.Sprintf("Cannot use %v as map key %v", v.Type(), keyt))
+}
@@ -0,0 +1,73 @@
+package gocsv
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type keyHero struct {
+	HeroID int    `csv:"heroId"`
+	Level  int    `csv:"level"`
+	Name   string `csv:"name"`
+}
+
+type heroKey struct {
+	HeroID int
+	Level  int
+}
+
+const keyCsv = "a,b,c\nheroId,level,name\nint,int,string\n1,1,a1\n1,2,a2\n2,1,b1\n"
+
+func TestReadMapStructKey(t *testing.T) {
+	m := make(map[heroKey]keyHero)
+	if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId, level", &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 || m[heroKey{1, 2}].Name != "a2" || m[heroKey{2, 1}].Name != "b1" {
+		t.Fatalf("map: %+v", m)
+	}
+}
+
+func TestReadMapStringKey(t *testing.T) {
+	m := make(map[string]*keyHero)
+	if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId,level", &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 || m["1_2"].Name != "a2" {
+		t.Fatalf("map: %+v", m)
+	}
+	m = make(map[string]*keyHero)
+	if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId,level", &m, WithKeySeparator(":")); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 || m["2:1"].Name != "b1" {
+		t.Fatalf("map of separator: %+v", m)
+	}
+}
+
+func TestReadMapNestedKey(t *testing.T) {
+	var m map[int]map[int]keyHero
+	if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId,level", &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || len(m[1]) != 2 || m[1][2].Name != "a2" || m[2][1].Name != "b1" {
+		t.Fatalf("map: %+v", m)
+	}
+}
+
+func TestReadMapKeyMismatch(t *testing.T) {
+	for name, out := range map[string]interface{}{
+		"nested levels": &map[int]map[int]map[string]keyHero{},
+		"struct fields": &map[struct{ HeroID int }]keyHero{},
+		"int key":       &map[int]keyHero{},
+	} {
+		if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId,level", out); err == nil {
+			t.Fatalf("%v: composite key is accepted", name)
+		}
+	}
+	m := make(map[string]keyHero)
+	if err := ReadMapContext(context.Background(), strings.NewReader(keyCsv), "heroId,", &m); err == nil {
+		t.Fatal("empty key field is accepted")
+	}
+}
@@ -0,0 +1,61 @@
+package gocsv
+
+import (
+	"strings"
+	"unicode"
+)
+
+//NameMapper map csv field name and struct field name (csv tag or go name),
+//they are matched when the mapped names are equal
+type NameMapper func(name string) string
+
+//LowerFirstMapper lower first letter, "ID" => "iD", "Name" => "name", it is default
+func LowerFirstMapper(name string) string {
+	return format(name)
+}
+
+//ExactMapper names must be equal
+func ExactMapper(name string) string {
+	return name
+}
+
+//IgnoreCaseMapper names are matched case-insensitively
+func IgnoreCaseMapper(name string) string {
+	return strings.ToLower(name)
+}
+
+//SnakeCaseMapper snake_case matches CamelCase, "goods_name" => "GoodsName"
+func SnakeCaseMapper(name string) string {
+	return CamelCase(name)
+}
+
+//WithNameMapper match csv field name and struct field name by mapper
+func WithNameMapper(mapper NameMapper) Option {
+	return func(o *options) {
+		if mapper != nil {
+			o.mapper = mapper
+		}
+	}
+}
+
+//CamelCase snake_case to CamelCase, "goods_name" => "GoodsName"
+func CamelCase(str string) string {
+	if str == "" {
+		return str
+	}
+	ret := make([]rune, 0)
+	isNeedUpper := true //首字母大写
+	for _, c := range str {
+		if c == '_' {
+			isNeedUpper = true //下划线大写
+			continue
+		}
+		if isNeedUpper {
+			ret = append(ret, unicode.ToUpper(c))
+		} else {
+			ret = append(ret, c)
+		}
+		isNeedUpper = false
+	}
+	return string(ret)
+}
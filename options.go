@@ -0,0 +1,182 @@
+package gocsv
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+)
+
+//Option option for read
+type Option func(*options)
+
+type options struct {
+	ctx       context.Context
+	workers   int
+	gbk       bool
+	name      string
+	progress  func(Progress)
+	keySep    string
+	duplicate DuplicatePolicy
+	mapper    NameMapper
+
+	disallowUnknown bool
+	columnWarning   func(*ColumnError)
+
+	commentRows    []string
+	commentColumns []string
+	skipBlank      bool
+	ragged         bool
+
+	layout Layout
+	target Target
+	typed  bool
+	header []Column
+
+	observer *observer
+}
+
+//observer receive header and decoded rows, it is used by TableSet
+type observer struct {
+	header func(h *header)
+	//row is stored in out, it replaces the row observed at index replace if replace >= 0, e.g. DuplicateLast
+	row func(r row, elmv reflect.Value, replace int)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		ctx:     context.Background(),
+		workers: 1,
+		keySep:  "_",
+		mapper:  LowerFirstMapper,
+		layout:  DefaultLayout,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+//DuplicatePolicy how ReadMap handles rows with same primary key in one read, entries already in the map are overwritten
+type DuplicatePolicy int
+
+const (
+	//DuplicateError return error with both line numbers, it is default
+	DuplicateError DuplicatePolicy = iota
+	//DuplicateFirst keep first row
+	DuplicateFirst
+	//DuplicateLast keep last row
+	DuplicateLast
+	//DuplicateGroup collect rows into map[K][]T, it is used for slice element whatever the policy is
+	DuplicateGroup
+)
+
+//fileOptions options of file APIs
+func fileOptions(file string, isGbk bool, opts []Option) *options {
+	o := newOptions(opts)
+	o.gbk = isGbk
+	o.name = file
+	return o
+}
+
+//streamOptions options of Context APIs
+func streamOptions(ctx context.Context, opts []Option) *options {
+	o := newOptions(opts)
+	if ctx != nil {
+		o.ctx = ctx
+	}
+	return o
+}
+
+//WithContext stop reading when ctx is done
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		if ctx != nil {
+			o.ctx = ctx
+		}
+	}
+}
+
+//WithWorkers convert rows to struct by n goroutines, the order of rows is kept.
+//n <= 0 means runtime.NumCPU()
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = runtime.NumCPU()
+		}
+		o.workers = n
+	}
+}
+
+//WithGBK transform gbk to utf8, for Context APIs which read from io.Reader
+func WithGBK(isGbk bool) Option {
+	return func(o *options) {
+		o.gbk = isGbk
+	}
+}
+
+//WithProgress call fn after each row is read, it reports rows and bytes processed
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+//WithKeySeparator separator of string key joined by many key fields, default is "_"
+func WithKeySeparator(sep string) Option {
+	return func(o *options) {
+		o.keySep = sep
+	}
+}
+
+//WithDuplicate policy of ReadMap for rows with same primary key
+func WithDuplicate(policy DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicate = policy
+	}
+}
+
+//defaultComments default comment prefixes
+var defaultComments = []string{"#", "//"}
+
+//WithCommentRows skip rows whose first value starts with one of prefixes, default prefixes are "#" and "//"
+func WithCommentRows(prefixes ...string) Option {
+	if len(prefixes) == 0 {
+		prefixes = defaultComments
+	}
+	return func(o *options) {
+		o.commentRows = prefixes
+	}
+}
+
+//WithCommentColumns skip columns whose field name starts with one of prefixes, default prefixes are "#" and "//"
+func WithCommentColumns(prefixes ...string) Option {
+	if len(prefixes) == 0 {
+		prefixes = defaultComments
+	}
+	return func(o *options) {
+		o.commentColumns = prefixes
+	}
+}
+
+//SkipBlankRows skip rows whose values are all empty
+func SkipBlankRows() Option {
+	return func(o *options) {
+		o.skipBlank = true
+	}
+}
+
+//WithTypedValues make Read decode bool, long, double, time, arrays and maps by their kinds, by default only int and float are decoded and others are strings
+func WithTypedValues() Option {
+	return func(o *options) {
+		o.typed = true
+	}
+}
+
+//AllowRaggedRows pad short rows with empty values and ignore extra values of long rows
+func AllowRaggedRows() Option {
+	return func(o *options) {
+		o.ragged = true
+	}
+}
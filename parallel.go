@@ -0,0 +1,133 @@
+package gocsv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//decodeFunc convert fields of one row to value
+type decodeFunc func(r row) (reflect.Value, error)
+
+//emitFunc receive converted value in the order of rows
+type emitFunc func(r row, elmv reflect.Value) error
+
+//decodeRows read rows of src, convert each row by decode and pass it to emit in the order of rows.
+//if o.workers > 1, rows are converted concurrently, emit is always called by one goroutine.
+func decodeRows(src rowSource, o *options, onHeader headerFunc, decode decodeFunc, emit emitFunc) error {
+	convert := func(r row) (reflect.Value, error) {
+		elmv, err := decode(r)
+		if verr, ok := err.(*ValidationError); ok {
+			verr.Name = o.name
+			return elmv, verr
+		}
+		if err != nil {
+			return elmv, readError(o.name, fmt.Sprintf("line %v: %v", r.line, err))
+		}
+		return elmv, nil
+	}
+
+	if o.workers <= 1 {
+		return src(o, onHeader, func(r row) error {
+			elmv, err := convert(r)
+			if err != nil {
+				return err
+			}
+			return emit(r, elmv)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(o.ctx)
+	defer cancel()
+
+	type job struct {
+		seq int
+		row row
+	}
+	type result struct {
+		seq  int
+		row  row
+		elmv reflect.Value
+		err  error
+	}
+	jobs := make(chan job, o.workers)
+	results := make(chan result, o.workers)
+
+	//reader, its error is sent when it is done
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		ro := *o
+		ro.ctx = ctx
+		seq := 0
+		readErr <- src(&ro, onHeader, func(r row) error {
+			select {
+			case jobs <- job{seq: seq, row: r}:
+				seq++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	//workers
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				elmv, err := convert(j.row)
+				select {
+				case results <- result{seq: j.seq, row: j.row, elmv: elmv, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	//emit in order, stop at first error
+	var err error
+	pending := make(map[int]result)
+	next := 0
+	for r := range results {
+		if err != nil {
+			continue
+		}
+		pending[r.seq] = r
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if p.err == nil {
+				p.err = emit(p.row, p.elmv)
+			}
+			if p.err != nil {
+				err = p.err
+				cancel()
+				break
+			}
+		}
+	}
+	//workers may exit on cancel before reader is done, wait for it,
+	//so progress and header callbacks are not called after return
+	cancel()
+	rerr := <-readErr
+	if err != nil {
+		return err
+	}
+	if rerr != nil {
+		return rerr
+	}
+	return o.ctx.Err()
+}
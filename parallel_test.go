@@ -0,0 +1,70 @@
+package gocsv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type parallelRow struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+}
+
+//parallelCsv csv of n rows, row of id bad breaks range of id
+func parallelCsv(n int, bad int) string {
+	var b strings.Builder
+	b.WriteString("id,name\nid,name\nint(1..100000),string\n")
+	for i := 1; i <= n; i++ {
+		id := i
+		if i == bad {
+			id = 0
+		}
+		fmt.Fprintf(&b, "%v,name%v\n", id, i)
+	}
+	return b.String()
+}
+
+func TestParallelOrder(t *testing.T) {
+	var list []parallelRow
+	err := ReadListContext(context.Background(), strings.NewReader(parallelCsv(1000, -1)), &list, WithWorkers(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1000 {
+		t.Fatalf("rows = %v, want 1000", len(list))
+	}
+	for i, r := range list {
+		if r.ID != i+1 {
+			t.Fatalf("row %v has id %v", i, r.ID)
+		}
+	}
+}
+
+func TestParallelCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var list []parallelRow
+	err := ReadListContext(ctx, strings.NewReader(parallelCsv(5000, -1)), &list, WithWorkers(8), WithProgress(func(p Progress) {
+		if p.Rows == 100 {
+			cancel()
+		}
+	}))
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestParallelFirstError(t *testing.T) {
+	var list []parallelRow
+	err := ReadListContext(context.Background(), strings.NewReader(parallelCsv(5000, 300)), &list, WithWorkers(8))
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("err = %v, want ValidationError", err)
+	}
+	//line of id 300 after 3 header rows
+	if verr.Line != 303 || verr.Column != "id" {
+		t.Fatalf("err = %+v, want line 303 of id", verr)
+	}
+}
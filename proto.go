@@ -0,0 +1,309 @@
+package gocsv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+//protoTypes proto3 type of go type kind
+var protoTypes = map[reflect.Kind]string{
+	reflect.Int:     "int32",
+	reflect.Int64:   "int64",
+	reflect.Float32: "float",
+	reflect.Float64: "double",
+	reflect.Bool:    "bool",
+	reflect.String:  "string",
+}
+
+//protoField field of row message, numbers follow column order from 1
+type protoField struct {
+	name     string
+	number   int
+	typ      string
+	repeated bool
+	kind     *Kind
+	desc     string
+	entry    []protoField //key and value fields of map entry, they are numbered 1 and 2
+}
+
+//protoType proto3 type of scalar go type, time is RFC3339 string
+func protoType(t reflect.Type) (string, bool) {
+	if t == timeType {
+		return "string", true
+	}
+	typ, ok := protoTypes[t.Kind()]
+	return typ, ok
+}
+
+//protoFields fields of columns, kind of reference column without kind is string
+func protoFields(cols []Column) ([]protoField, error) {
+	fields := make([]protoField, 0, len(cols))
+	names := make(map[string]bool)
+	for i, col := range cols {
+		kind := col.Kind
+		if kind == "" {
+			kind = "string"
+		}
+		k, err := LookupKind(kind)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Column \"%v\": %v", col.Name, err))
+		}
+		t, repeated := k.Type, false
+		if t.Kind() == reflect.Slice {
+			t, repeated = t.Elem(), true
+		}
+		var entry []protoField
+		typ, ok := protoType(t)
+		if t.Kind() == reflect.Map {
+			keyTyp, kok := protoType(t.Key())
+			valueTyp, vok := protoType(t.Elem())
+			typ, ok = fmt.Sprintf("map<%v, %v>", keyTyp, valueTyp), kok && vok
+			entry = []protoField{{name: "key", number: 1, typ: keyTyp}, {name: "value", number: 2, typ: valueTyp}}
+		}
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Column \"%v\" of kind \"%v\" has no proto type", col.Name, kind))
+		}
+		name := protoName(col.Name, i+1)
+		for n := 2; names[name]; n++ {
+			name = fmt.Sprintf("%v_%v", protoName(col.Name, i+1), n)
+		}
+		names[name] = true
+		fields = append(fields, protoField{name: name, number: i + 1, typ: typ, repeated: repeated, kind: k, desc: col.Desc, entry: entry})
+	}
+	return fields, nil
+}
+
+//protoName field name of column, characters other than ASCII letters, digits and "_" are "_"
+func protoName(name string, number int) string {
+	ret := strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' {
+			return c
+		}
+		return '_'
+	}, name)
+	if strings.Trim(ret, "_") == "" {
+		return fmt.Sprintf("field_%v", number)
+	}
+	if ret[0] >= '0' && ret[0] <= '9' {
+		ret = "f_" + ret
+	}
+	return ret
+}
+
+//ProtoMessage proto3 messages of columns, message has a field of each column numbered in column order
+//and message+"Table" has its rows, the rows are written by WriteProto
+func ProtoMessage(message string, cols []Column) (string, error) {
+	fields, err := protoFields(cols)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "message %v {\n", message)
+	for _, f := range fields {
+		for _, line := range strings.Split(strings.TrimSpace(f.desc), "\n") {
+			if line = strings.TrimRight(line, "\r "); line != "" {
+				fmt.Fprintf(&buf, "  // %v\n", line)
+			}
+		}
+		buf.WriteString("  ")
+		if f.repeated {
+			buf.WriteString("repeated ")
+		}
+		fmt.Fprintf(&buf, "%v %v = %v;\n", f.typ, f.name, f.number)
+	}
+	fmt.Fprintf(&buf, "}\n\nmessage %vTable {\n  repeated %v rows = 1;\n}\n", message, message)
+	return buf.String(), nil
+}
+
+//WriteProto write rows of csv file to w in protobuf wire format of message XTable of ProtoMessage,
+//invalid value is a ValidationError
+func WriteProto(file string, isGbk bool, w io.Writer, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("read csv file: %v, error: %v", file, rerr))
+		}
+	}()
+	return writeProto(fileSource(file), fileOptions(file, isGbk, opts), w)
+}
+
+//WriteProtoContext write rows of csv from r to w in protobuf wire format
+func WriteProtoContext(ctx context.Context, r io.Reader, w io.Writer, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = readError("", rerr)
+		}
+	}()
+	return writeProto(streamSource(r), streamOptions(ctx, opts), w)
+}
+
+//WriteProtoFile write rows of csv file to protobuf file, out is kept if csv is invalid
+func WriteProtoFile(file string, isGbk bool, out string, opts ...Option) error {
+	return writeOutput(out, func(w io.Writer) error {
+		return WriteProto(file, isGbk, w, opts...)
+	})
+}
+
+func writeProto(src rowSource, o *options, w io.Writer) error {
+	var fields []protoField
+	var names []string
+	bw := bufio.NewWriter(w)
+	var msg []byte
+	err := src(o, func(h *header) error {
+		var err error
+		fields, err = protoFields(h.columns())
+		names = h.names
+		return err
+	}, func(r row) error {
+		msg = msg[:0]
+		for i, f := range r.fields {
+			var err error
+			if msg, err = fields[i].append(msg, f.Value); err != nil {
+				return &ValidationError{Name: o.name, Line: r.line, Column: names[i], Value: f.Value, Rule: fields[i].kind.Name}
+			}
+		}
+		//field "rows = 1" of XTable
+		rec := protowire(nil, 1, 2)
+		rec = binary.AppendUvarint(rec, uint64(len(msg)))
+		if _, err := bw.Write(rec); err != nil {
+			return err
+		}
+		_, err := bw.Write(msg)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+//protowire append tag of field number and wire type
+func protowire(b []byte, number int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(number)<<3|uint64(wire))
+}
+
+//append value of field, empty and zero values are omitted as proto3 does,
+//repeated numbers are packed
+func (f protoField) append(b []byte, value string) ([]byte, error) {
+	if value == "" {
+		return b, nil
+	}
+	v, err := f.kind.Parse(value)
+	if err != nil {
+		return b, err
+	}
+	rv := reflect.ValueOf(v)
+	if f.entry != nil {
+		return f.appendMap(b, rv)
+	}
+	if !f.repeated {
+		return f.appendValue(b, rv, true)
+	}
+	if rv.Len() == 0 {
+		return b, nil
+	}
+	if f.typ == "string" {
+		for i := 0; i < rv.Len(); i++ {
+			s := protoString(rv.Index(i))
+			b = protowire(b, f.number, 2)
+			b = binary.AppendUvarint(b, uint64(len(s)))
+			b = append(b, s...)
+		}
+		return b, nil
+	}
+	packed := make([]byte, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		if packed, err = f.appendValue(packed, rv.Index(i), false); err != nil {
+			return b, err
+		}
+	}
+	b = protowire(b, f.number, 2)
+	b = binary.AppendUvarint(b, uint64(len(packed)))
+	return append(b, packed...), nil
+}
+
+//appendMap append entries of map in order of keys, each entry is a message of key and value
+func (f protoField) appendMap(b []byte, rv reflect.Value) ([]byte, error) {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Kind() == reflect.String {
+			return keys[i].String() < keys[j].String()
+		}
+		return keys[i].Int() < keys[j].Int()
+	})
+	var err error
+	entry := make([]byte, 0)
+	for _, key := range keys {
+		if entry, err = f.entry[0].appendValue(entry[:0], key, true); err != nil {
+			return b, err
+		}
+		if entry, err = f.entry[1].appendValue(entry, rv.MapIndex(key), true); err != nil {
+			return b, err
+		}
+		b = protowire(b, f.number, 2)
+		b = binary.AppendUvarint(b, uint64(len(entry)))
+		b = append(b, entry...)
+	}
+	return b, nil
+}
+
+//appendValue append value with tag or packed value without tag
+func (f protoField) appendValue(b []byte, v reflect.Value, tag bool) ([]byte, error) {
+	if tag && v.IsZero() {
+		return b, nil
+	}
+	switch f.typ {
+	case "int32":
+		if v.Int() < math.MinInt32 || v.Int() > math.MaxInt32 {
+			return b, errors.New("int32 overflow")
+		}
+		fallthrough
+	case "int64":
+		if tag {
+			b = protowire(b, f.number, 0)
+		}
+		//negative number is 10 bytes of two's complement
+		return binary.AppendUvarint(b, uint64(v.Int())), nil
+	case "bool":
+		if tag {
+			b = protowire(b, f.number, 0)
+		}
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case "double":
+		if tag {
+			b = protowire(b, f.number, 1)
+		}
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Float())), nil
+	case "float":
+		if tag {
+			b = protowire(b, f.number, 5)
+		}
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v.Float()))), nil
+	}
+	s := protoString(v)
+	b = protowire(b, f.number, 2)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...), nil
+}
+
+//protoString value of string field
+func protoString(v reflect.Value) string {
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time))
+	}
+	return v.String()
+}
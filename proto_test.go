@@ -0,0 +1,147 @@
+package gocsv
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const protoCsv = "a,b,c,d,e,f,g\nid,hp,name,tags,rate,ok,alias\nint,long,string,[]int,double,bool,[]string\n" +
+	"1,-1,ab,1|2|300,0.5,true,x|yz\n" +
+	"0,0,,,0,false,\n" +
+	"-5,,,,,,\n"
+
+func TestProtoMessage(t *testing.T) {
+	cols, err := ReadHeaderContext(context.Background(), strings.NewReader(protoCsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := ProtoMessage("Hero", cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "message Hero {\n" +
+		"  // a\n  int32 id = 1;\n" +
+		"  // b\n  int64 hp = 2;\n" +
+		"  // c\n  string name = 3;\n" +
+		"  // d\n  repeated int32 tags = 4;\n" +
+		"  // e\n  double rate = 5;\n" +
+		"  // f\n  bool ok = 6;\n" +
+		"  // g\n  repeated string alias = 7;\n" +
+		"}\n\nmessage HeroTable {\n  repeated Hero rows = 1;\n}\n"
+	if msg != want {
+		t.Fatalf("message:\n%v\nwant:\n%v", msg, want)
+	}
+}
+
+func TestWriteProto(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteProtoContext(context.Background(), strings.NewReader(protoCsv), &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		//rows = 1, message of 41 bytes
+		0x0a, 0x29,
+		//id = 1
+		0x08, 0x01,
+		//hp = -1, 10 bytes of two's complement
+		0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+		//name = "ab"
+		0x1a, 0x02, 'a', 'b',
+		//tags = [1, 2, 300] packed
+		0x22, 0x04, 0x01, 0x02, 0xac, 0x02,
+		//rate = 0.5, fixed64 little endian
+		0x29, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0, 0x3f,
+		//ok = true
+		0x30, 0x01,
+		//alias = ["x", "yz"], strings are not packed
+		0x3a, 0x01, 'x', 0x3a, 0x02, 'y', 'z',
+
+		//zero and empty values are omitted
+		0x0a, 0x00,
+
+		//id = -5 of int32 is sign extended to 10 bytes
+		0x0a, 0x0b,
+		0x08, 0xfb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bytes:\n% x\nwant:\n% x", buf.Bytes(), want)
+	}
+}
+
+func TestWriteProtoInvalid(t *testing.T) {
+	for _, value := range []string{"3000000000", "abc"} {
+		d := "a\nid\nint\n1\n" + value + "\n"
+		err := WriteProtoContext(context.Background(), strings.NewReader(d), &bytes.Buffer{})
+		verr, ok := err.(*ValidationError)
+		if !ok || verr.Line != 5 || verr.Value != value {
+			t.Fatalf("value %v: err = %v, want ValidationError at line 5", value, err)
+		}
+	}
+}
+
+func TestWriteProtoFileKeepsOld(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"hero.csv": "a\nid\nint\n1\n"})
+	out := filepath.Join(dir, "hero.pb")
+	if err := WriteProtoFile(filepath.Join(dir, "hero.csv"), false, out); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := os.ReadFile(out)
+	os.WriteFile(filepath.Join(dir, "hero.csv"), []byte("a\nid\nint\nabc\n"), 0644)
+	if err := WriteProtoFile(filepath.Join(dir, "hero.csv"), false, out); err == nil {
+		t.Fatal("invalid csv is written")
+	}
+	if content, _ := os.ReadFile(out); !bytes.Equal(content, old) {
+		t.Fatalf("out = % x, want old % x", content, old)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(files) > 0 {
+		t.Fatalf("temp files are left: %v", files)
+	}
+}
+
+func TestWriteProtoMap(t *testing.T) {
+	d := "a\nattrs\nmap[string]int\nb:0|a:1\n"
+	cols, err := ReadHeaderContext(context.Background(), strings.NewReader(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := ProtoMessage("Hero", cols)
+	if err != nil || !strings.Contains(msg, "  map<string, int32> attrs = 1;\n") {
+		t.Fatalf("message:\n%v\nerr: %v", msg, err)
+	}
+	var buf bytes.Buffer
+	if err := WriteProtoContext(context.Background(), strings.NewReader(d), &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x0a, 0x0c,
+		//entry {key = "a", value = 1}
+		0x0a, 0x05, 0x0a, 0x01, 'a', 0x10, 0x01,
+		//entry {key = "b"}, zero value is omitted
+		0x0a, 0x03, 0x0a, 0x01, 'b',
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bytes:\n% x\nwant:\n% x", buf.Bytes(), want)
+	}
+}
+
+func TestWriteProtoTime(t *testing.T) {
+	d := "a,b\nat,days\ntime,[]time\n2024-01-02,2024-01-02|2024-01-03 10:00:00\n"
+	var buf bytes.Buffer
+	if err := WriteProtoContext(context.Background(), strings.NewReader(d), &buf); err != nil {
+		t.Fatal(err)
+	}
+	//times are RFC3339 strings
+	want := []byte{0x0a, 0x42, 0x0a, 0x14}
+	want = append(want, "2024-01-02T00:00:00Z"...)
+	want = append(want, 0x12, 0x14)
+	want = append(want, "2024-01-02T00:00:00Z"...)
+	want = append(want, 0x12, 0x14)
+	want = append(want, "2024-01-03T10:00:00Z"...)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bytes:\n% x\nwant:\n% x", buf.Bytes(), want)
+	}
+}
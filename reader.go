@@ -0,0 +1,282 @@
+package gocsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+//Progress progress of reading
+type Progress struct {
+	Rows  int   //rows processed
+	Bytes int64 //bytes of rows processed, for gbk source it is bytes read ahead in 4KB buffers
+	Total int64 //size of source, 0 if unknown
+}
+
+//row fields of one csv line
+type row struct {
+	line   int
+	fields []Field
+}
+
+//header header rows of csv
+type header struct {
+	descs       []string
+	names       []string
+	kinds       []string
+	targets     []string
+	constraints []constraint
+}
+
+//headerFunc check header before rows are read, it can be nil
+type headerFunc func(h *header) error
+
+//rowSource read header and rows, then pass them to onHeader and handle
+type rowSource func(o *options, onHeader headerFunc, handle func(row) error) error
+
+//fieldsHandle handle of fields only
+func fieldsHandle(handle func([]Field) error) func(row) error {
+	return func(r row) error {
+		return handle(r.fields)
+	}
+}
+
+//fileSource rows of csv file
+func fileSource(file string) rowSource {
+	return func(o *options, onHeader headerFunc, handle func(row) error) error {
+		if file == "" {
+			return errors.New("read csv file parameter is empty.")
+		}
+		fi, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer fi.Close()
+		return readRows(fi, o, onHeader, handle)
+	}
+}
+
+//streamSource rows of reader
+func streamSource(r io.Reader) rowSource {
+	return func(o *options, onHeader headerFunc, handle func(row) error) error {
+		if r == nil {
+			return errors.New("read csv reader parameter is nil.")
+		}
+		return readRows(r, o, onHeader, handle)
+	}
+}
+
+//readRows read header and rows one by one
+func readRows(r io.Reader, o *options, onHeader headerFunc, handle func(row) error) error {
+	counter := &countReader{r: r}
+	total := sizeOf(r)
+	reader := newCsvReader(counter, o.gbk)
+	//行长度由下面检查
+	reader.FieldsPerRecord = -1
+
+	//表头：描述、字段名、类型
+	layout := o.layout
+	if layout.Name < 0 {
+		return errors.New("Layout has no field name row")
+	}
+	lines := make([][]string, 0, layout.rows())
+	for len(lines) < layout.rows() {
+		line, err := reader.Read()
+		if err == io.EOF && o.name == "" {
+			return errors.New("Csv is invalid")
+		}
+		if err == io.EOF {
+			return errors.New(fmt.Sprintf("Csv %v is invalid", o.name))
+		}
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+	fieldNum := len(lines[layout.Name])
+	for i, line := range lines {
+		if len(line) != fieldNum && !o.ragged {
+			return lineError(o.name, i+1, len(line), fieldNum)
+		}
+		lines[i] = fit(trimAll(line), fieldNum)
+	}
+	headerRow := func(i int) []string {
+		if i < 0 {
+			return make([]string, fieldNum)
+		}
+		return lines[i]
+	}
+	descs, names, kinds, targets := headerRow(layout.Desc), headerRow(layout.Name), headerRow(layout.Kind), headerRow(layout.Target)
+	//去掉注释列和其他目标的列
+	cols := make([]int, 0, fieldNum)
+	h := &header{}
+	for j := 0; j < fieldNum; j++ {
+		if hasPrefix(names[j], o.commentColumns) || !o.target.Match(targets[j]) {
+			continue
+		}
+		kind, c, err := parseKind(kinds[j])
+		if err != nil {
+			return readError(o.name, err)
+		}
+		cols = append(cols, j)
+		h.descs = append(h.descs, descs[j])
+		h.names = append(h.names, names[j])
+		h.kinds = append(h.kinds, kind)
+		h.targets = append(h.targets, targets[j])
+		h.constraints = append(h.constraints, c)
+	}
+	if onHeader != nil {
+		if err := onHeader(h); err != nil {
+			return err
+		}
+	}
+	if o.observer != nil {
+		o.observer.header(h)
+	}
+
+	checker := newChecker(o.name, h)
+	rows := 0
+	for {
+		//检查是否已取消
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		line, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		lineNum, _ := reader.FieldPos(0)
+		//跳过注释行、空行
+		if hasPrefix(trim(line[0]), o.commentRows) || (o.skipBlank && isBlank(line)) {
+			continue
+		}
+		if len(line) != fieldNum && !o.ragged {
+			return lineError(o.name, lineNum, len(line), fieldNum)
+		}
+		line = fit(line, fieldNum)
+		itemFields := make([]Field, len(cols), len(cols))
+		for i, j := range cols {
+			itemFields[i] = Field{
+				Name:  h.names[i],
+				Value: trim(line[j]),
+				Kind:  h.kinds[i],
+			}
+		}
+		r := row{line: lineNum, fields: itemFields}
+		if err := checker.check(r); err != nil {
+			return err
+		}
+		//如果返回解析错误，直接返回
+		if err := handle(r); err != nil {
+			return err
+		}
+		rows++
+		if o.progress != nil {
+			//offset of utf8 after gbk decoder does not match bytes of source
+			processed := reader.InputOffset()
+			if o.gbk {
+				processed = counter.n
+			}
+			o.progress(Progress{Rows: rows, Bytes: processed, Total: total})
+		}
+	}
+}
+
+//lineError line has wrong number of fields
+func lineError(name string, line int, num int, want int) error {
+	return readError(name, fmt.Sprintf("line %v has %v fields, want %v", line, num, want))
+}
+
+//fit pad or cut strs to n
+func fit(strs []string, n int) []string {
+	if len(strs) >= n {
+		return strs[:n]
+	}
+	return append(strs, make([]string, n-len(strs))...)
+}
+
+//hasPrefix s has one of prefixes
+func hasPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+//isBlank all values are empty
+func isBlank(line []string) bool {
+	for _, v := range line {
+		if trim(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+//trimAll trim each string
+func trimAll(strs []string) []string {
+	ret := make([]string, len(strs))
+	for i, s := range strs {
+		ret[i] = trim(s)
+	}
+	return ret
+}
+
+//newCsvReader csv reader, transform gbk to utf8 if isGbk
+func newCsvReader(r io.Reader, isGbk bool) *csv.Reader {
+	if !isGbk {
+		return csv.NewReader(r)
+	}
+	return csv.NewReader(gbkReader(r))
+}
+
+//gbkReader transform gbk to utf8
+func gbkReader(r io.Reader) io.Reader {
+	return transform.NewReader(r, simplifiedchinese.GBK.NewDecoder())
+}
+
+//countReader count bytes read
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+//sizeOf size of reader if known
+func sizeOf(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := v.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	case interface{ Size() int64 }:
+		return v.Size()
+	case interface{ Len() int }:
+		return int64(v.Len())
+	}
+	return 0
+}
+
+//readError error of reading
+func readError(name string, err interface{}) error {
+	if name == "" {
+		return errors.New(fmt.Sprintf("read csv error: %v", err))
+	}
+	return errors.New(fmt.Sprintf("read csv file: %v, error: %v", name, err))
+}
@@ -0,0 +1,197 @@
+package gocsv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//LoadFunc load table from csv file
+type LoadFunc func(file string, isGbk bool, opts ...Option) (interface{}, error)
+
+//Registry tables of a directory, table "mobile" is read from "mobile.csv".
+//Get returns a read-only snapshot, it is swapped atomically when the file is reloaded,
+//a table which fails to reload keeps its last good snapshot.
+type Registry struct {
+	dir   string
+	isGbk bool
+	opts  []Option
+
+	mu       sync.RWMutex //guard tables, Get does not wait for reload
+	tables   map[string]*regTable
+	reloadMu sync.Mutex //one Load or Reload at a time
+}
+
+//regTable table of Registry
+type regTable struct {
+	name    string
+	file    string
+	load    LoadFunc
+	value   atomic.Value //snapshot
+	modTime time.Time
+	size    int64
+	missing bool //file is not found in last check
+}
+
+//NewRegistry new registry of dir, opts are used by all tables
+func NewRegistry(dir string, isGbk bool, opts ...Option) *Registry {
+	return &Registry{dir: dir, isGbk: isGbk, opts: opts, tables: make(map[string]*regTable)}
+}
+
+//Register register table name with load func
+func (r *Registry) Register(name string, load LoadFunc) error {
+	if name == "" || load == nil {
+		return errors.New("Register table name and load func must not be empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tables[name]; ok {
+		return errors.New(fmt.Sprintf("Table \"%v\" is registered", name))
+	}
+	r.tables[name] = &regTable{name: name, file: filepath.Join(r.dir, name+".csv"), load: load}
+	return nil
+}
+
+//RegisterList register table read by ReadList, list is a value of its type, e.g. []Mobile(nil)
+func (r *Registry) RegisterList(name string, list interface{}) error {
+	listt := reflect.TypeOf(list)
+	if listt == nil || listt.Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf("Register table \"%v\" of %T, it must be slice", name, list))
+	}
+	return r.Register(name, func(file string, isGbk bool, opts ...Option) (interface{}, error) {
+		out := reflect.New(listt)
+		if err := ReadList(file, isGbk, out.Interface(), opts...); err != nil {
+			return nil, err
+		}
+		return out.Elem().Interface(), nil
+	})
+}
+
+//RegisterMap register table read by ReadMap, m is a value of its type, e.g. map[int]*Mobile(nil)
+func (r *Registry) RegisterMap(name string, keyField string, m interface{}) error {
+	mapt := reflect.TypeOf(m)
+	if mapt == nil || mapt.Kind() != reflect.Map {
+		return errors.New(fmt.Sprintf("Register table \"%v\" of %T, it must be map", name, m))
+	}
+	return r.Register(name, func(file string, isGbk bool, opts ...Option) (interface{}, error) {
+		out := reflect.New(mapt)
+		if err := ReadMap(file, isGbk, keyField, out.Interface(), opts...); err != nil {
+			return nil, err
+		}
+		return out.Elem().Interface(), nil
+	})
+}
+
+//Get snapshot of table, nil if it is not loaded
+func (r *Registry) Get(name string) interface{} {
+	r.mu.RLock()
+	t, ok := r.tables[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return t.value.Load()
+}
+
+//Load load all tables, errors of all tables are returned together
+func (r *Registry) Load() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	errs := make([]error, 0)
+	for _, t := range r.sorted() {
+		if err := r.reload(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//Reload reload tables whose files are changed since last load, it returns names of reloaded tables
+func (r *Registry) Reload() ([]string, error) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	reloaded := make([]string, 0)
+	errs := make([]error, 0)
+	for _, t := range r.sorted() {
+		info, err := os.Stat(t.file)
+		if err != nil {
+			//report once until file comes back
+			if !t.missing {
+				errs = append(errs, err)
+			}
+			t.missing = true
+			continue
+		}
+		if !t.missing && info.ModTime().Equal(t.modTime) && info.Size() == t.size {
+			continue
+		}
+		if err := r.reload(t); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reloaded = append(reloaded, t.name)
+	}
+	return reloaded, errors.Join(errs...)
+}
+
+//Watch poll files every interval and reload changed tables until ctx is done,
+//onReload is called for each reloaded table and each failure, it can be nil
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, onReload func(names []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			names, err := r.Reload()
+			if onReload != nil && (len(names) > 0 || err != nil) {
+				onReload(names, err)
+			}
+		}
+	}
+}
+
+//sorted tables by name, they are loaded without holding mu
+func (r *Registry) sorted() []*regTable {
+	r.mu.RLock()
+	tables := make([]*regTable, 0, len(r.tables))
+	for _, t := range r.tables {
+		tables = append(tables, t)
+	}
+	r.mu.RUnlock()
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].name < tables[j].name
+	})
+	return tables
+}
+
+//reload load table and swap snapshot if it is good, fields other than value are guarded by reloadMu
+func (r *Registry) reload(t *regTable) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("load table: %v, error: %v", t.name, rerr))
+		}
+	}()
+	info, err := os.Stat(t.file)
+	t.missing = err != nil
+	if err != nil {
+		return err
+	}
+	//bad file is not reloaded until it is changed again
+	t.modTime, t.size = info.ModTime(), info.Size()
+	value, err := t.load(t.file, r.isGbk, r.opts...)
+	if err != nil {
+		return errors.New(fmt.Sprintf("load table: %v, error: %v", t.name, err))
+	}
+	t.value.Store(value)
+	return nil
+}
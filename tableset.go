@@ -0,0 +1,247 @@
+package gocsv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//TableSet tables loaded together, a column of kind "ref:table.column" references rows of another table.
+//after all tables are loaded, every reference is checked, and struct fields tagged `csv:",ref=column"`
+//of type *T are set to the referenced rows.
+type TableSet struct {
+	isGbk  bool
+	opts   []Option
+	tables []*setTable
+}
+
+//setTable table of TableSet
+type setTable struct {
+	name     string
+	file     string
+	keyField string //empty for list
+	out      interface{}
+
+	h     *header
+	rows  []row
+	elems []reflect.Value //decoded struct of each row
+	//elems are stored in out, it is false for map[K]T whose elements are copies
+	stable bool
+	index  map[string]map[string]int //column => value => row
+}
+
+//NewTableSet new table set, opts are used by all tables
+func NewTableSet(isGbk bool, opts ...Option) *TableSet {
+	return &TableSet{isGbk: isGbk, opts: opts}
+}
+
+//AddList add table read by ReadList, name is used by "ref:name.column", rows are appended to out as ReadList does
+func (s *TableSet) AddList(name string, file string, out interface{}) *TableSet {
+	s.tables = append(s.tables, &setTable{name: name, file: file, out: out})
+	return s
+}
+
+//AddMap add table read by ReadMap, name is used by "ref:name.column"
+func (s *TableSet) AddMap(name string, file string, keyField string, out interface{}) *TableSet {
+	s.tables = append(s.tables, &setTable{name: name, file: file, keyField: keyField, out: out})
+	return s
+}
+
+//Load read all tables, check references and set ref fields, all errors are returned together
+func (s *TableSet) Load() error {
+	errs := make([]error, 0)
+	for _, t := range s.tables {
+		if err := s.load(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, t := range s.tables {
+		errs = append(errs, s.check(t)...)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, t := range s.tables {
+		if err := s.resolve(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//load read table and keep its rows
+func (s *TableSet) load(t *setTable) error {
+	t.h, t.rows, t.elems, t.index = nil, nil, nil, make(map[string]map[string]int)
+	obs := &observer{
+		header: func(h *header) {
+			t.h = h
+		},
+		row: func(r row, elmv reflect.Value, replace int) {
+			if replace >= 0 {
+				t.rows[replace], t.elems[replace] = r, elmv
+				return
+			}
+			t.rows = append(t.rows, r)
+			t.elems = append(t.elems, elmv)
+		},
+	}
+	opts := append(append([]Option{}, s.opts...), func(o *options) {
+		o.observer = obs
+	})
+	if t.keyField != "" {
+		elmt := reflect.TypeOf(t.out).Elem()
+		for elmt.Kind() == reflect.Map || elmt.Kind() == reflect.Slice {
+			elmt = elmt.Elem()
+		}
+		t.stable = elmt.Kind() == reflect.Ptr
+		return ReadMap(t.file, s.isGbk, t.keyField, t.out, opts...)
+	}
+	//rows are appended after elements already in out, e.g. of last Load
+	start := 0
+	if outv := reflect.ValueOf(t.out); outv.Kind() == reflect.Ptr && outv.Elem().Kind() == reflect.Slice {
+		start = outv.Elem().Len()
+	}
+	err := ReadList(t.file, s.isGbk, t.out, opts...)
+	if err != nil {
+		return err
+	}
+	//[]T is addressable after all rows are appended
+	t.stable = true
+	slicev := reflect.ValueOf(t.out).Elem()
+	for i := range t.elems {
+		t.elems[i] = reflect.Indirect(slicev.Index(start + i))
+	}
+	return nil
+}
+
+//table table of name
+func (s *TableSet) table(name string) *setTable {
+	for _, t := range s.tables {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+//lookup row of value in column of table
+func (t *setTable) lookup(column string, value string) (int, bool, error) {
+	index, ok := t.index[column]
+	if !ok {
+		col := -1
+		for i, name := range t.h.names {
+			if name == column {
+				col = i
+			}
+		}
+		if col < 0 {
+			return 0, false, errors.New(fmt.Sprintf("Csv %v has no column \"%v\"", t.file, column))
+		}
+		index = make(map[string]int)
+		for i, r := range t.rows {
+			if _, ok := index[r.fields[col].Value]; !ok {
+				index[r.fields[col].Value] = i
+			}
+		}
+		t.index[column] = index
+	}
+	i, ok := index[value]
+	return i, ok, nil
+}
+
+//refTarget table and column of reference "table.column"
+func (s *TableSet) refTarget(t *setTable, col int) (*setTable, string, error) {
+	ref := t.h.constraints[col].ref
+	parts := strings.SplitN(ref, ".", 2)
+	target := s.table(parts[0])
+	if target == nil {
+		return nil, "", errors.New(fmt.Sprintf("Csv %v column \"%v\" references unknown table \"%v\"", t.file, t.h.names[col], parts[0]))
+	}
+	return target, parts[1], nil
+}
+
+//check all references of table exist, empty value references nothing
+func (s *TableSet) check(t *setTable) []error {
+	errs := make([]error, 0)
+	for col, c := range t.h.constraints {
+		if c.ref == "" {
+			continue
+		}
+		target, column, err := s.refTarget(t, col)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, r := range t.rows {
+			value := r.fields[col].Value
+			if value == "" {
+				continue
+			}
+			_, ok, err := target.lookup(column, value)
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+			if !ok {
+				errs = append(errs, &ValidationError{Name: t.file, Line: r.line, Column: t.h.names[col], Value: value, Rule: "ref:" + c.ref})
+			}
+		}
+	}
+	return errs
+}
+
+//resolve set fields tagged `csv:",ref=column"` to referenced rows
+func (s *TableSet) resolve(t *setTable) error {
+	if len(t.elems) == 0 {
+		return nil
+	}
+	elmt := t.elems[0].Type()
+	for i := 0; i < elmt.NumField(); i++ {
+		tag, err := parseTag(elmt.Field(i))
+		if err != nil {
+			return err
+		}
+		if tag.ref == "" {
+			continue
+		}
+		col := -1
+		for j, name := range t.h.names {
+			if name == tag.ref {
+				col = j
+			}
+		}
+		if col < 0 || t.h.constraints[col].ref == "" {
+			return errors.New(fmt.Sprintf("Field %v.%v: csv %v has no reference column \"%v\"", elmt, elmt.Field(i).Name, t.file, tag.ref))
+		}
+		target, column, err := s.refTarget(t, col)
+		if err != nil {
+			return err
+		}
+		fieldt := elmt.Field(i).Type
+		if fieldt.Kind() != reflect.Ptr || len(target.elems) > 0 && fieldt.Elem() != target.elems[0].Type() {
+			return errors.New(fmt.Sprintf("Field %v.%v must be pointer of %v row", elmt, elmt.Field(i).Name, target.name))
+		}
+		if !t.stable {
+			return errors.New(fmt.Sprintf("Field %v.%v cannot be set, map element must be pointer", elmt, elmt.Field(i).Name))
+		}
+		if !target.stable {
+			return errors.New(fmt.Sprintf("Field %v.%v cannot reference %v, its map element must be pointer", elmt, elmt.Field(i).Name, target.name))
+		}
+		for j, r := range t.rows {
+			elmv := t.elems[j]
+			k, ok, err := target.lookup(column, r.fields[col].Value)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			elmv.Field(i).Set(target.elems[k].Addr())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package gocsv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type setCat struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+}
+
+type setMob struct {
+	ID     int     `csv:"id"`
+	Cat    int     `csv:"cat"`
+	CatRef *setCat `csv:",ref=cat"`
+}
+
+//writeFiles write csv files of name => content to a temp dir
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestTableSetRef(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"cat.csv": "a,b\nid,name\nint,string\n1,phone\n2,pad\n",
+		"mob.csv": "a,b\nid,cat\nint,ref:cat.id\n10,2\n11,1\n12,\n",
+	})
+	//rows are appended after existing elements
+	cats := []setCat{{ID: 99}}
+	var mobs []setMob
+	set := NewTableSet(false).AddList("cat", filepath.Join(dir, "cat.csv"), &cats).AddList("mob", filepath.Join(dir, "mob.csv"), &mobs)
+	for load := 1; load <= 2; load++ {
+		if err := set.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if len(cats) != 1+2*load || len(mobs) != 3*load {
+			t.Fatalf("load %v: %v cats and %v mobs", load, len(cats), len(mobs))
+		}
+		//rows of this load
+		cs, ms := cats[len(cats)-2:], mobs[len(mobs)-3:]
+		if ms[0].CatRef != &cs[1] || ms[1].CatRef != &cs[0] {
+			t.Fatalf("load %v: refs %+v %+v, want %+v %+v", load, ms[0].CatRef, ms[1].CatRef, cs[1], cs[0])
+		}
+		if ms[2].CatRef != nil {
+			t.Fatalf("load %v: empty ref is %+v", load, ms[2].CatRef)
+		}
+	}
+}
+
+func TestTableSetMissingRef(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"cat.csv": "a,b\nid,name\nint,string\n1,phone\n",
+		"mob.csv": "a,b\nid,cat\nint,ref:cat.id\n10,1\n11,3\n",
+	})
+	var cats []setCat
+	var mobs []setMob
+	err := NewTableSet(false).AddList("cat", filepath.Join(dir, "cat.csv"), &cats).AddList("mob", filepath.Join(dir, "mob.csv"), &mobs).Load()
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Line != 5 || verr.Value != "3" || verr.Rule != "ref:cat.id" {
+		t.Fatalf("err = %v, want ref error at line 5", err)
+	}
+}
+
+func TestTableSetDuplicate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"cat.csv": "a,b\nid,name\nint,string\n1,old\n2,pad\n1,new\n",
+		"mob.csv": "a,b\nid,cat\nint,ref:cat.id\n10,1\n11,2\n",
+	})
+	for _, c := range []struct {
+		policy DuplicatePolicy
+		name   string
+	}{{DuplicateFirst, "old"}, {DuplicateLast, "new"}} {
+		cats := map[int]*setCat{}
+		var mobs []setMob
+		err := NewTableSet(false, WithDuplicate(c.policy)).AddMap("cat", filepath.Join(dir, "cat.csv"), "id", &cats).AddList("mob", filepath.Join(dir, "mob.csv"), &mobs).Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cats[1].Name != c.name || mobs[0].CatRef != cats[1] || mobs[1].CatRef != cats[2] {
+			t.Fatalf("policy %v: cats[1] = %+v, refs %+v %+v", c.policy, cats[1], mobs[0].CatRef, mobs[1].CatRef)
+		}
+	}
+}
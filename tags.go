@@ -0,0 +1,74 @@
+package gocsv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//fieldTag csv tag of struct field, e.g. `csv:"id,required,min=1,max=100"`
+type fieldTag struct {
+	name     string  //csv field name, default is go field name
+	required bool    //csv must have the column, and value must not be empty
+	rest     bool    //map[string]string or []Field for columns without struct field
+	unique   bool    //values are unique in all rows
+	ref      string  //pointer field set by TableSet to the row referenced by column ref
+	rules    []*rule //validation rules
+}
+
+//parseTag parse csv tag of struct field, regex= must be the last option because it may contain comma
+func parseTag(sf reflect.StructField) (fieldTag, error) {
+	parts := strings.Split(sf.Tag.Get("csv"), ",")
+	tag := fieldTag{name: trim(parts[0])}
+	if tag.name == "" {
+		tag.name = sf.Name
+	}
+	for i := 1; i < len(parts); i++ {
+		opt := trim(parts[i])
+		switch opt {
+		case "":
+			//e.g. `csv:"id,"`
+			continue
+		case "rest":
+			tag.rest = true
+			continue
+		case "unique":
+			tag.unique = true
+			continue
+		case "required":
+			tag.required = true
+		}
+		if strings.HasPrefix(opt, "ref=") {
+			tag.ref = trim(strings.TrimPrefix(opt, "ref="))
+			continue
+		}
+		if strings.HasPrefix(opt, "regex=") {
+			opt = strings.Join(parts[i:], ",")
+			i = len(parts)
+		}
+		rl, err := parseRule(opt)
+		if err != nil {
+			return tag, errors.New(fmt.Sprintf("Field %v: %v", sf.Name, err))
+		}
+		tag.rules = append(tag.rules, rl)
+	}
+	return tag, nil
+}
+
+var (
+	restMapType  = reflect.TypeOf(map[string]string(nil))
+	restListType = reflect.TypeOf([]Field(nil))
+)
+
+//addRest add field to rest field
+func addRest(rest reflect.Value, f Field) {
+	if rest.Type() == restListType {
+		rest.Set(reflect.Append(rest, reflect.ValueOf(f)))
+		return
+	}
+	if rest.IsNil() {
+		rest.Set(reflect.MakeMap(restMapType))
+	}
+	rest.SetMapIndex(reflect.ValueOf(f.Name), reflect.ValueOf(f.Value))
+}
@@ -8,7 +8,6 @@ import (
 	"flag"
 	"io/ioutil"
 	"path"
-	"unicode"
 	"github.com/foolin/gocsv"
 	"encoding/json"
 )
@@ -54,7 +53,7 @@ func main() {
 			}
 
 			name := filename(info.Name());
-			list, err := gocsv.ReadLines(path.Join(*csvpath, info.Name()), true)
+			list, err := readLines(path.Join(*csvpath, info.Name()))
 			if err != nil {
 				log.Fatalf("read csv: %v, error: %v", info.Name(), err)
 				return
@@ -75,8 +74,8 @@ func main() {
 		}
 
 	} else {
-		name := upper(filename(fileInfo.Name()));
-		list, err := gocsv.ReadLines(path.Join(*csvpath, fileInfo.Name()), true)
+		name := gocsv.CamelCase(filename(fileInfo.Name()));
+		list, err := readLines(*csvpath)
 		if err != nil {
 			log.Fatalf("read csv error: %v", err)
 			return
@@ -108,6 +107,15 @@ func main() {
 	log.Print("generator done!")
 }
 
+//readLines check constraints of kind row, then read all lines
+func readLines(file string) ([][]string, error) {
+	err := gocsv.Validate(file, true)
+	if err != nil {
+		return nil, err
+	}
+	return gocsv.ReadLines(file, true)
+}
+
 func writeJsonFile(outFile string, data interface{}) error {
 	//mkdir
 	outAbs, _ := filepath.Abs(outFile)
@@ -128,28 +136,6 @@ func writeJsonFile(outFile string, data interface{}) error {
 	return nil
 }
 
-func upper(str string) string {
-	if str == "" {
-		return str
-	}
-	ret := make([]rune, 0)
-	isNeedUpper := true        //首字母大写
-	for _, c := range str {
-		//log.Printf("%#v\n", c)
-		if c == '_' {
-			isNeedUpper = true        //下划线大写
-			continue
-		}
-		if isNeedUpper {
-			ret = append(ret, unicode.ToUpper(c))
-		} else {
-			ret = append(ret, c)
-		}
-		isNeedUpper = false
-	}
-	return string(ret)
-}
-
 func filename(filename string) string {
 	name := filepath.Base(filename)
 	return strings.TrimSuffix(name, filepath.Ext(filename))
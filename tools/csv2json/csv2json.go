@@ -8,13 +8,14 @@ import (
 	"flag"
 	"io/ioutil"
 	"path"
-	"unicode"
 	"github.com/foolin/gocsv"
 	"encoding/json"
 )
 
 var csvpath = flag.String("csv", "", "exmaple: xxx/data/demo.csv or dir: xxx/data")
 var outpath = flag.String("out", "", "exmaple: xxx/data/demo.json or dir: xxx/out")
+var target = flag.String("target", "", "export columns of target: client|server, csv must have target row (c/s/cs) after kind row")
+var typed = flag.Bool("typed", false, "write bool, long, double, time and array kinds as json values instead of strings")
 
 func main() {
 	//abs, err := filepath.Abs("./../")
@@ -29,6 +30,14 @@ func main() {
 		log.Panic(err)
 		return
 	}
+	opts, err := gocsv.TargetOptions(*target)
+	if err != nil {
+		log.Panic(err)
+		return
+	}
+	if *typed {
+		opts = append(opts, gocsv.WithTypedValues())
+	}
 	isOutOneFile := false
 	if *outpath != "" && strings.ToLower(filepath.Ext(*outpath)) == ".json" {
 		isOutOneFile = true
@@ -54,7 +63,7 @@ func main() {
 			}
 
 			name := filename(info.Name());
-			list, err := gocsv.Read(path.Join(*csvpath, info.Name()), true)
+			list, err := gocsv.Read(path.Join(*csvpath, info.Name()), true, opts...)
 			if err != nil {
 				log.Fatalf("read csv: %v, error: %v", info.Name(), err)
 				return
@@ -75,8 +84,8 @@ func main() {
 		}
 
 	} else {
-		name := upper(filename(fileInfo.Name()));
-		list, err := gocsv.Read(path.Join(*csvpath, fileInfo.Name()), true)
+		name := gocsv.CamelCase(filename(fileInfo.Name()));
+		list, err := gocsv.Read(*csvpath, true, opts...)
 		if err != nil {
 			log.Fatalf("read csv error: %v", err)
 			return
@@ -128,28 +137,6 @@ func writeJsonFile(outFile string, data interface{}) error {
 	return nil
 }
 
-func upper(str string) string {
-	if str == "" {
-		return str
-	}
-	ret := make([]rune, 0)
-	isNeedUpper := true        //首字母大写
-	for _, c := range str {
-		//log.Printf("%#v\n", c)
-		if c == '_' {
-			isNeedUpper = true        //下划线大写
-			continue
-		}
-		if isNeedUpper {
-			ret = append(ret, unicode.ToUpper(c))
-		} else {
-			ret = append(ret, c)
-		}
-		isNeedUpper = false
-	}
-	return string(ret)
-}
-
 func filename(filename string) string {
 	name := filepath.Base(filename)
 	return strings.TrimSuffix(name, filepath.Ext(filename))
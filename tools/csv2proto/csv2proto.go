@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/foolin/gocsv"
+	"github.com/foolin/gocsv/tools/internal/convert"
+)
+
+var csvpath = flag.String("csv", "", "exmaple: xxx/data/demo.csv or dir: xxx/data")
+var outpath = flag.String("out", "", "exmaple: dir: xxx/out, demo.csv is written to xxx/out/demo.pb")
+var gbk = flag.Bool("gbk", true, "exmaple: true / false")
+var target = flag.String("target", "", "export columns of target: client|server, csv must have target row (c/s/cs) after kind row")
+
+func main() {
+	flag.Parse()
+	if *csvpath == "" {
+		flag.Usage()
+		return
+	}
+	opts, err := gocsv.TargetOptions(*target)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = convert.Run(*csvpath, *outpath, ".pb", func(csvfile string, outfile string) error {
+		return gocsv.WriteProtoFile(csvfile, *gbk, outfile, opts...)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print("csv2proto done!")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/foolin/gocsv"
+)
+
+var csvpath = flag.String("csv", "", "exmaple: dir: xxx/data")
+var outpath = flag.String("out", "", "exmaple: xxx/tables.zip")
+var version = flag.String("version", "", "exmaple: 1.0.3")
+var verify = flag.String("verify", "", "verify bundle and print manifest, exmaple: xxx/tables.zip")
+var gbk = flag.Bool("gbk", true, "exmaple: true / false")
+
+func main() {
+	flag.Parse()
+	if *verify != "" {
+		b, err := gocsv.OpenBundle(*verify)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printManifest(&b.Manifest)
+		return
+	}
+	if *csvpath == "" || *outpath == "" {
+		flag.Usage()
+		return
+	}
+	m, err := gocsv.WriteBundle(*csvpath, *gbk, *version, *outpath)
+	if err != nil {
+		log.Fatalf("write bundle: %v, error: %v", *outpath, err)
+	}
+	log.Printf("write file: %v", *outpath)
+	printManifest(m)
+}
+
+func printManifest(m *gocsv.Manifest) {
+	log.Printf("version: %v, hash: %v", m.Version, m.Hash)
+	for _, t := range m.Tables {
+		log.Printf("table: %v, rows: %v, hash: %v", t.Name, t.Rows, t.Hash)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/foolin/gocsv"
+)
+
+//csBackend c# classes for unity, they are read from json of csv2json by JsonUtility
+var csBackend = &backend{ext: ".cs", table: csTable, all: csTables, file: csFile}
+
+//csTypes c# type of go type kind
+var csTypes = map[reflect.Kind]string{
+	reflect.Int:     "int",
+	reflect.Int64:   "long",
+	reflect.Float32: "float",
+	reflect.Float64: "double",
+	reflect.Bool:    "bool",
+	reflect.String:  "string",
+}
+
+//csKeywords keywords of c#, they are prefixed with "@"
+var csKeywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields(`abstract as base bool break byte case catch char checked class const continue
+		decimal default delegate do double else enum event explicit extern false finally fixed float for foreach goto
+		if implicit in int interface internal is lock long namespace new null object operator out override params
+		private protected public readonly ref return sbyte sealed short sizeof stackalloc static string struct switch
+		this throw true try typeof uint ulong unchecked unsafe ushort using virtual void volatile while`) {
+		csKeywords[k] = true
+	}
+}
+
+//csType c# type of kind, kind of reference column without kind is string
+func csType(column gocsv.Column) (string, error) {
+	if column.Kind == "" {
+		return "string", nil
+	}
+	k, err := gocsv.LookupKind(column.Kind)
+	if err != nil {
+		return "", fmt.Errorf("column \"%v\": %v", column.Name, err)
+	}
+	t := k.Type
+	array := ""
+	if t.Kind() == reflect.Slice {
+		t, array = t.Elem(), "[]"
+	}
+	name, ok := csTypes[t.Kind()]
+	//json of time is RFC3339 string
+	if t == reflect.TypeOf(time.Time{}) {
+		name, ok = "string", true
+	}
+	if !ok {
+		return "", fmt.Errorf("column \"%v\": kind \"%v\" is not supported by JsonUtility", column.Name, column.Kind)
+	}
+	return name + array, nil
+}
+
+//csField c# field of column, JsonUtility matches json keys with field names, so the name is kept
+func csField(name string) (string, error) {
+	for i, c := range name {
+		if !(unicode.IsLetter(c) || c == '_' || i > 0 && unicode.IsDigit(c)) {
+			return "", fmt.Errorf("name \"%v\" is not a valid c# field, JsonUtility needs json key as field name", name)
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("name is empty")
+	}
+	if csKeywords[name] {
+		return "@" + name, nil
+	}
+	return name, nil
+}
+
+//csSummary xml doc comment of description
+func csSummary(desc string, indent string) string {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return ""
+	}
+	desc = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(desc)
+	ret := indent + "/// <summary>\n"
+	for _, line := range strings.Split(desc, "\n") {
+		ret = ret + indent + "/// " + strings.TrimRight(line, "\r ") + "\n"
+	}
+	return ret + indent + "/// </summary>\n"
+}
+
+//csTable serializable class of row and XTable with Load and LoadMap of csv2json array
+func csTable(source string, typename string, columns []gocsv.Column, pk string) (string, error) {
+	key, err := primaryKey(columns, pk)
+	if err != nil {
+		return "", err
+	}
+	typename = identifier(typename, "Table")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n    /// <summary>\n    /// %v rows of %v\n    /// </summary>\n    [Serializable]\n    public class %v\n    {\n", typename, source, typename)
+	for i, column := range columns {
+		kind, err := csType(column)
+		if err != nil {
+			return "", err
+		}
+		field, err := csField(column.Name)
+		if err != nil {
+			return "", fmt.Errorf("column %v: %v", i+1, err)
+		}
+		buf.WriteString(csSummary(column.Desc, "        "))
+		fmt.Fprintf(&buf, "        public %v %v;\n", kind, field)
+	}
+	buf.WriteString("    }\n")
+	keyType, err := csType(key)
+	if err != nil {
+		return "", err
+	}
+	keyField, _ := csField(key.Name)
+	r := strings.NewReplacer("{{X}}", typename, "{{key}}", key.Name, "{{F}}", keyField, "{{K}}", keyType)
+	buf.WriteString(r.Replace(csLoaderCode))
+	return buf.String(), nil
+}
+
+const csLoaderCode = `
+    /// <summary>
+    /// {{X}}Table loads {{X}} from json array of csv2json -typed
+    /// </summary>
+    public static class {{X}}Table
+    {
+        [Serializable]
+        private class Rows
+        {
+            public {{X}}[] rows;
+        }
+
+        /// <summary>
+        /// Load rows in csv order
+        /// </summary>
+        public static {{X}}[] Load(string json)
+        {
+            return JsonUtility.FromJson<Rows>("{\"rows\":" + json + "}").rows;
+        }
+
+        /// <summary>
+        /// LoadMap rows keyed by {{key}}, duplicate {{key}} throws ArgumentException
+        /// </summary>
+        public static Dictionary<{{K}}, {{X}}> LoadMap(string json)
+        {
+            var map = new Dictionary<{{K}}, {{X}}>();
+            foreach (var row in Load(json))
+            {
+                map.Add(row.{{F}}, row);
+            }
+            return map;
+        }
+    }
+`
+
+//csTables Tables of json object written by csv2json -typed -out xxx.json, its keys are csv file names
+func csTables(typenames []string, sources []string) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\n    /// <summary>\n    /// Tables all tables of json written by csv2json -typed -out xxx.json\n    /// </summary>\n    [Serializable]\n    public class Tables\n    {\n")
+	for i, typename := range typenames {
+		field, err := csField(filename(sources[i]))
+		if err != nil {
+			return "", fmt.Errorf("csv %v: %v", sources[i], err)
+		}
+		fmt.Fprintf(&buf, "        public %v[] %v;\n", typename, field)
+	}
+	buf.WriteString("\n        /// <summary>\n        /// Load all tables\n        /// </summary>\n        public static Tables Load(string json)\n        {\n            return JsonUtility.FromJson<Tables>(json);\n        }\n    }\n")
+	return buf.String(), nil
+}
+
+//csFile c# file of namespace dir
+func csFile(dir string, source string, hash string, body string, single bool) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by github.com/foolin/gocsv/tools/generator. DO NOT EDIT.\n// source: %v\n// source header sha256: %v\n\n", source, hash)
+	buf.WriteString("using System;\nusing System.Collections.Generic;\nusing UnityEngine;\n\n")
+	fmt.Fprintf(&buf, "namespace %v\n{\n%v}\n", identifier(dir, "Tables"), strings.TrimPrefix(body, "\n"))
+	return buf.Bytes(), nil
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
-	"os"
-	"encoding/csv"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/encoding/simplifiedchinese"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"github.com/foolin/gocsv"
+	"unicode"
+	"os"
 	"fmt"
 	"strings"
 	"log"
@@ -13,13 +15,18 @@ import (
 	"flag"
 	"io/ioutil"
 	"path"
-"unicode"
 )
 
 
 var csvpath = flag.String("csvpath", "", "exmaple: xxx/data/demo.csv or dir: xxx/data")
 var outpath = flag.String("outpath", "", "exmaple: xxx/data/demo.go or dir: xxx/data")
 var utf8 = flag.Bool("utf8", false, "utf8 is: true|false")
+var target = flag.String("target", "", "generate columns of target: client|server, csv must have target row (c/s/cs) after kind row")
+var single = flag.Bool("single", false, "generate all csv of dir into one file with Tables of all tables, outpath is the file or its dir")
+var lang = flag.String("lang", "go", "language of generated code: go|cs|ts|proto")
+var check = flag.Bool("check", false, "do not write, exit 1 if generated files are out of date with csv headers")
+var infer = flag.Bool("infer", false, "write header template xxx.header.csv of csv with field name row only, kinds are proposed by values")
+var pk = flag.String("pk", "", "primary key column of LoadXMap and table Get, default is the first unique column or the first column")
 
 func main() {
 	//abs, err := filepath.Abs("./../")
@@ -34,6 +41,25 @@ func main() {
 		log.Panic(err)
 		return
 	}
+	opts, err := gocsv.TargetOptions(*target)
+	if err != nil {
+		log.Panic(err)
+		return
+	}
+	if *infer {
+		err := inferHeaders(*csvpath, *outpath, fileInfo.IsDir(), *utf8)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		log.Print("generator done!")
+		return
+	}
+	b, ok := backends[*lang]
+	if !ok {
+		log.Fatalf("lang \"%v\" is not supported", *lang)
+		return
+	}
 	if fileInfo.IsDir(){
 		infos, err := ioutil.ReadDir(*csvpath)
 		if err != nil {
@@ -43,23 +69,47 @@ func main() {
 		if *outpath == ""{
 			*outpath = *csvpath
 		}
+		if *single {
+			csvfiles := make([]string, 0)
+			for _, info := range infos {
+				if filepath.Ext(info.Name()) == ".csv" {
+					csvfiles = append(csvfiles, path.Join(*csvpath, info.Name()))
+				}
+			}
+			outfile := *outpath
+			if filepath.Ext(outfile) != b.ext {
+				outfile = path.Join(outfile, "tables"+b.ext)
+			}
+			err := writeSingle(b, csvfiles, outfile, *utf8, *pk, opts)
+			if err != nil {
+				log.Fatal(err)
+				return
+			}
+			log.Print("generator done!")
+			return
+		}
+		failed := false
 		for _, info := range infos{
 			ext := filepath.Ext(info.Name())
 			if ext != ".csv"{
 				continue
 			}
-			gofile := strings.Replace(info.Name(), ".csv", ".go", -1)
-			err := write(path.Join(*csvpath, info.Name()), path.Join(*outpath, gofile), *utf8)
+			gofile := strings.Replace(info.Name(), ".csv", b.ext, -1)
+			err := write(b, path.Join(*csvpath, info.Name()), path.Join(*outpath, gofile), *utf8, *pk, opts)
 			if err != nil {
 				log.Printf("generator file: %v error: %v", info.Name(), err)
+				failed = true
 				continue
 			}
 		}
+		if failed {
+			os.Exit(1)
+		}
 
 	} else{
-		err := write(*csvpath, *outpath, *utf8)
+		err := write(b, *csvpath, *outpath, *utf8, *pk, opts)
 		if err != nil {
-			log.Panic(err)
+			log.Fatal(err)
 			return
 		}
 	}
@@ -67,37 +117,15 @@ func main() {
 
 }
 
-func write(csvfile string, outfile string, isUtf8 bool) error {
-	//open file
-	fi, err := os.Open(csvfile)
+func write(b *backend, csvfile string, outfile string, isUtf8 bool, pk string, opts []gocsv.Option) error {
+	columns, err := gocsv.ReadHeader(csvfile, !isUtf8, opts...)
 	if err != nil {
 		return err
 	}
-	defer fi.Close()
-	//get reader
-	var reader *csv.Reader
-	if isUtf8 {
-		reader = csv.NewReader(fi)
-	} else {
-		//transform gbk to utf8
-		r := transform.NewReader(fi, simplifiedchinese.GBK.NewDecoder())
-		reader = csv.NewReader(r)
-	}
-
-	lines, err := reader.ReadAll()
-	if err != nil {
-		return errors.New(fmt.Sprintf("read error: %v", err))
-	}
-	lineNum := len(lines)
-	if (lineNum < 3) {
-		return errors.New(fmt.Sprintf("Csv %v is invalid"))
-	}
-	names, fields, kinds := lines[0], lines[1], lines[2]
-	fieldNum := len(names)
 	filename := filename(csvfile)
 
 	if outfile == ""{
-		outfile = strings.Replace(csvfile, ".csv", ".go", -1)
+		outfile = strings.Replace(csvfile, ".csv", b.ext, -1)
 	}
 	//packname
 	packname := ""
@@ -109,26 +137,122 @@ func write(csvfile string, outfile string, isUtf8 bool) error {
 	}
 
 
-	code := fmt.Sprintf("// Code generated by github.com/foolin/gocsv.\n// source: %v\n// DO NOT EDIT! \n\npackage %v\n\ntype %v struct {\n", filepath.Base(csvfile), packname, upper(filename))
-	for j := 0; j < fieldNum; j++ {
-		name := names[j]
-		field := fields[j]
-		kind := kinds[j]
-		if kind == "float" {
-			kind = "float32"
+	body, err := b.table(filepath.Base(csvfile), gocsv.CamelCase(filename), columns, pk)
+	if err != nil {
+		return err
+	}
+	code, err := b.file(packname, filepath.Base(csvfile), headerHash(columns), body, false)
+	if err != nil {
+		return err
+	}
+	return writeFile(outfile, code)
+}
+
+//writeSingle write tables of csvfiles to one file with Tables of all tables
+func writeSingle(b *backend, csvfiles []string, outfile string, isUtf8 bool, pk string, opts []gocsv.Option) error {
+	outAbs, _ := filepath.Abs(outfile)
+	dir := filepath.Base(filepath.Dir(outAbs))
+
+	var body bytes.Buffer
+	sources := make([]string, 0, len(csvfiles))
+	typenames := make([]string, 0, len(csvfiles))
+	tables := make([][]gocsv.Column, 0, len(csvfiles))
+	//names of Tables, LoadAll and loadTable, and LoadX, LoadXMap, XTable, XData of each table
+	used := map[string]bool{"Tables": true, "LoadAll": true}
+	errs := make([]error, 0)
+	for _, csvfile := range csvfiles {
+		columns, err := gocsv.ReadHeader(csvfile, !isUtf8, opts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		typename := identifier(gocsv.CamelCase(filename(csvfile)), "Table")
+		for n := 2; tableUsed(used, typename); n++ {
+			typename = fmt.Sprintf("%v%v", identifier(gocsv.CamelCase(filename(csvfile)), "Table"), n)
+		}
+		for _, name := range tableNames(typename) {
+			used[name] = true
+		}
+		code, err := b.table(filepath.Base(csvfile), typename, columns, pk)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("generator file: %v error: %v", csvfile, err))
+			continue
+		}
+		body.WriteString(code)
+		sources = append(sources, filepath.Base(csvfile))
+		typenames = append(typenames, typename)
+		tables = append(tables, columns)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	all, err := b.all(typenames, sources)
+	if err != nil {
+		return err
+	}
+	body.WriteString(all)
+	code, err := b.file(dir, strings.Join(sources, ", "), headerHash(tables...), body.String(), true)
+	if err != nil {
+		return err
+	}
+	return writeFile(outfile, code)
+}
+
+//tableNames package names declared by table of typename
+func tableNames(typename string) []string {
+	return []string{typename, "Load" + typename, "Load" + typename + "Map", typename + "Table", typename + "Data"}
+}
+
+//tableUsed any name of table is declared by other tables
+func tableUsed(used map[string]bool, typename string) bool {
+	for _, name := range tableNames(typename) {
+		if used[name] {
+			return true
 		}
-		code = code + fmt.Sprintf("\t%v %v `csv:\"%v\"` //%v\n", upper(field), kind, field, name)
 	}
-	code = code + "}\n"
+	return false
+}
+
+//backend code generator of a language
+type backend struct {
+	ext string //extension of generated file
+	//code of table
+	table func(source string, typename string, columns []gocsv.Column, pk string) (string, error)
+	//code of Tables, which has all tables of single file
+	all func(typenames []string, sources []string) (string, error)
+	//file of code, dir is name of its directory
+	file func(dir string, source string, hash string, body string, single bool) ([]byte, error)
+}
 
+//backends backend of each lang
+var backends = map[string]*backend{
+	"go": goBackend,
+	"cs": csBackend,
+	"ts": tsBackend,
+	"proto": protoBackend,
+}
+
+//writeFile write code to outfile, its dir is created if not exists,
+//with -check it only compares code with outfile
+func writeFile(outfile string, code []byte) error {
+	if *check {
+		old, err := ioutil.ReadFile(outfile)
+		if err != nil || !bytes.Equal(old, code) {
+			return fmt.Errorf("%v is out of date, run the generator again", outfile)
+		}
+		log.Printf("up to date: %v", outfile)
+		return nil
+	}
+	outAbs, _ := filepath.Abs(outfile)
 	//mkdir
-	err = os.MkdirAll(filepath.Dir(outAbs), 0755)
+	err := os.MkdirAll(filepath.Dir(outAbs), 0755)
 	if err != nil {
 		return err
 	}
 
 	//write file
-	err = ioutil.WriteFile(outfile, []byte(code), 0755)
+	err = ioutil.WriteFile(outfile, code, 0755)
 	if err != nil {
 		return err
 	}
@@ -136,26 +260,64 @@ func write(csvfile string, outfile string, isUtf8 bool) error {
 	return nil
 }
 
-func upper(str string) string {
-	if str == ""{
-		return str
+//primaryKey column of name, default is the first unique column or the first column
+func primaryKey(columns []gocsv.Column, name string) (gocsv.Column, error) {
+	if len(columns) == 0 {
+		return gocsv.Column{}, fmt.Errorf("csv has no column")
 	}
-	ret := make([]rune, 0)
-	isNeedUpper  := true	//首字母大写
-	for _, c := range str{
-		//log.Printf("%#v\n", c)
-		if c == '_'{
-			isNeedUpper = true	//下划线大写
-			continue
+	for _, column := range columns {
+		if name != "" && column.Name == name || name == "" && column.Unique {
+			return column, nil
 		}
-		if isNeedUpper{
-			ret = append(ret, unicode.ToUpper(c))
-		}else{
-			ret = append(ret, c)
+	}
+	if name != "" {
+		return gocsv.Column{}, fmt.Errorf("primary key column \"%v\" is not found", name)
+	}
+	return columns[0], nil
+}
+
+//headerHash sha256 of csv headers, the generated code only depends on them
+func headerHash(tables ...[]gocsv.Column) string {
+	h := sha256.New()
+	for _, columns := range tables {
+		for _, column := range columns {
+			fmt.Fprintf(h, "%#v\n", column)
 		}
-		isNeedUpper = false
+		fmt.Fprintln(h)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//identifier exported go identifier of name, words separated by "_", "-", spaces or other symbols are joined in camel case,
+//name starting with a digit or non-ASCII letter is prefixed with "X", def is used if name has no letter or digit
+func identifier(name string, def string) string {
+	words := strings.FieldsFunc(name, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	ret := ""
+	for _, word := range words {
+		ret = ret + gocsv.CamelCase(word)
+	}
+	if ret == "" {
+		return def
+	}
+	if !unicode.IsUpper([]rune(ret)[0]) {
+		ret = "X" + ret
+	}
+	return ret
+}
+
+//docComment comment lines of description
+func docComment(desc string, indent string) string {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return ""
+	}
+	ret := ""
+	for _, line := range strings.Split(desc, "\n") {
+		ret = ret + indent + "// " + strings.TrimRight(line, "\r ") + "\n"
 	}
-	return string(ret)
+	return ret
 }
 
 func filename(filename string) string {
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	csvfile, outfile := filepath.Join(dir, "mob.csv"), filepath.Join(dir, "mob.go")
+	if err := os.WriteFile(csvfile, []byte("a,b\nid,name\nint,string\n1,x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	*check = true
+	defer func() { *check = false }()
+	if err := write(goBackend, csvfile, outfile, true, "", nil); err == nil {
+		t.Fatal("missing file is up to date")
+	}
+	if _, err := os.Stat(outfile); !os.IsNotExist(err) {
+		t.Fatalf("-check writes file: %v", err)
+	}
+
+	*check = false
+	if err := write(goBackend, csvfile, outfile, true, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	code, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*check = true
+	//rows are not in header hash
+	if err := os.WriteFile(csvfile, []byte("a,b\nid,name\nint,string\n1,x\n2,y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := write(goBackend, csvfile, outfile, true, "", nil); err != nil {
+		t.Fatalf("generated file is out of date: %v", err)
+	}
+	//new column
+	if err := os.WriteFile(csvfile, []byte("a,b,c\nid,name,lv\nint,string,int\n1,x,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = write(goBackend, csvfile, outfile, true, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "out of date") {
+		t.Fatalf("stale file error: %v", err)
+	}
+	now, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(now, code) {
+		t.Fatal("-check writes stale file")
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/foolin/gocsv"
+)
+
+//goBackend go structs read by gocsv
+var goBackend = &backend{ext: ".go", table: tableCode, all: goTables, file: goFile}
+
+//goFile go file of package dir
+func goFile(dir string, source string, hash string, body string, single bool) ([]byte, error) {
+	imports := make([]string, 0)
+	if single {
+		imports = append(imports, "errors", "io/fs")
+	}
+	//fields of time kind
+	if strings.Contains(body, "time.Time") {
+		imports = append(imports, "time")
+	}
+	return fileCode(packageName(dir), source, hash, body, imports...)
+}
+
+//goTables Tables of all tables and LoadAll
+func goTables(typenames []string, sources []string) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\n// Tables all tables of package\ntype Tables struct {\n")
+	for _, typename := range typenames {
+		fmt.Fprintf(&buf, "\t%v *%vTable\n", typename, typename)
+	}
+	buf.WriteString("}\n\n// LoadAll read all tables from fsys, table is read from its csv file in fsys, e.g. os.DirFS(\"data\"),\n// all errors are returned together\n")
+	buf.WriteString("func LoadAll(fsys fs.FS, opts ...gocsv.Option) (*Tables, error) {\n\tt := &Tables{\n")
+	for _, typename := range typenames {
+		fmt.Fprintf(&buf, "\t\t%v: &%vTable{},\n", typename, typename)
+	}
+	buf.WriteString("\t}\n\terrs := []error{\n")
+	for i, typename := range typenames {
+		fmt.Fprintf(&buf, "\t\tloadTable(fsys, %q, t.%v, opts),\n", sources[i], typename)
+	}
+	buf.WriteString("\t}\n\treturn t, errors.Join(errs...)\n}\n")
+	buf.WriteString(loadTableCode)
+
+	return buf.String(), nil
+}
+
+//goType go type of kind, kind of reference column without kind is string
+func goType(column gocsv.Column) (string, error) {
+	if column.Kind == "" {
+		return "string", nil
+	}
+	k, err := gocsv.LookupKind(column.Kind)
+	if err != nil {
+		return "", fmt.Errorf("column \"%v\": %v", column.Name, err)
+	}
+	return k.Type.String(), nil
+}
+
+//loader LoadX, LoadXMap and table holder XTable of type X keyed by column key of field and go type keyType
+func loader(typename string, key string, field string, keyType string) string {
+	//key in comments is one line, in code it is a quoted string
+	r := strings.NewReplacer("{{X}}", typename, "{{key}}", strings.Join(strings.Fields(key), " "), "{{qkey}}", strconv.Quote(key), "{{F}}", field, "{{K}}", keyType, "{{target}}", targetCode(*target))
+	return r.Replace(loaderCode)
+}
+
+//targetCode options of -target prepended to opts of loaders, csv of target has target row after kind row
+func targetCode(target string) string {
+	if target == "" {
+		return ""
+	}
+	t, err := gocsv.ParseTarget(target)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\topts = append([]gocsv.Option{gocsv.WithLayout(gocsv.TargetLayout), gocsv.WithTarget(%q)}, opts...)\n", string(t))
+}
+
+const loaderCode = `
+//Load{{X}} read {{X}} list from r
+func Load{{X}}(r io.Reader, opts ...gocsv.Option) ([]{{X}}, error) {
+{{target}}	list := make([]{{X}}, 0)
+	if err := gocsv.ReadListContext(context.Background(), r, &list, opts...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+//Load{{X}}Map read {{X}} map keyed by {{key}} from r
+func Load{{X}}Map(r io.Reader, opts ...gocsv.Option) (map[{{K}}]*{{X}}, error) {
+{{target}}	m := make(map[{{K}}]*{{X}})
+	if err := gocsv.ReadMapContext(context.Background(), r, {{qkey}}, &m, opts...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//{{X}}Table rows of {{X}} keyed by {{key}}, it is safe for concurrent use
+type {{X}}Table struct {
+	mu   sync.RWMutex
+	list []*{{X}}
+	rows map[{{K}}]*{{X}}
+}
+
+//{{X}}Data table of {{X}}, it is empty until Load
+var {{X}}Data = &{{X}}Table{rows: make(map[{{K}}]*{{X}})}
+
+//Load read rows from r and replace all rows, rows are kept if r is bad
+func (t *{{X}}Table) Load(r io.Reader, opts ...gocsv.Option) error {
+{{target}}	list := make([]*{{X}}, 0)
+	if err := gocsv.ReadListContext(context.Background(), r, &list, opts...); err != nil {
+		return err
+	}
+	m := make(map[{{K}}]*{{X}})
+	for _, row := range list {
+		if _, ok := m[row.{{F}}]; ok {
+			return fmt.Errorf("duplicate %v %v", {{qkey}}, row.{{F}})
+		}
+		m[row.{{F}}] = row
+	}
+	t.mu.Lock()
+	t.list, t.rows = list, m
+	t.mu.Unlock()
+	return nil
+}
+
+//Get row of {{key}}, nil if it is not found
+func (t *{{X}}Table) Get(id {{K}}) *{{X}} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.rows[id]
+}
+
+//All rows in csv order
+func (t *{{X}}Table) All() []*{{X}} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.list
+}
+`
+
+//tableCode struct and loaders of table from source csv
+func tableCode(source string, typename string, columns []gocsv.Column, pk string) (string, error) {
+	key, err := primaryKey(columns, pk)
+	if err != nil {
+		return "", err
+	}
+	typename = identifier(typename, "Table")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "//%v rows of %v\ntype %v struct {\n", typename, source, typename)
+	fields := make(map[string]bool)
+	keyField := ""
+	for i, column := range columns {
+		kind, err := goType(column)
+		if err != nil {
+			return "", err
+		}
+		//goods_name and goodsName are GoodsName and GoodsName2
+		field := identifier(column.Name, fmt.Sprintf("Column%v", i+1))
+		for n := 2; fields[field]; n++ {
+			field = fmt.Sprintf("%v%v", identifier(column.Name, fmt.Sprintf("Column%v", i+1)), n)
+		}
+		fields[field] = true
+		if column.Name == key.Name && keyField == "" {
+			keyField = field
+		}
+		buf.WriteString(docComment(column.Desc, "\t"))
+		tag, err := fieldTag(column.Name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "\t%v %v %v\n", field, kind, tag)
+	}
+	buf.WriteString("}\n")
+	keyType, err := goType(key)
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString(loader(typename, key.Name, keyField, keyType))
+	return buf.String(), nil
+}
+
+//fileCode go file of tables, it is formatted by go/format
+func fileCode(packname string, source string, hash string, body string, imports ...string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by github.com/foolin/gocsv/tools/generator. DO NOT EDIT.\n// source: %v\n// source header sha256: %v\n\npackage %v\n\n", source, hash, packname)
+	imports = append([]string{"context", "fmt", "io", "sync"}, imports...)
+	sort.Strings(imports)
+	buf.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString("\n\t\"github.com/foolin/gocsv\"\n)\n\n")
+	buf.WriteString(body)
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code of %v is invalid: %v", source, err)
+	}
+	return code, nil
+}
+
+//packageName go package name of dir name, keyword is suffixed with "_"
+func packageName(name string) string {
+	name = strings.Map(func(c rune) rune {
+		if c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_') {
+			return unicode.ToLower(c)
+		}
+		return -1
+	}, name)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "tables" + name
+	}
+	if token.IsKeyword(name) {
+		name = name + "_"
+	}
+	return name
+}
+
+//fieldTag csv tag of column, it is quoted if name has backquote
+func fieldTag(name string) (string, error) {
+	//options of csv tag are separated by comma
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("column \"%v\" has \",\", it cannot be name of csv tag", name)
+	}
+	tag := "csv:" + strconv.Quote(name)
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag), nil
+	}
+	return "`" + tag + "`", nil
+}
+
+const loadTableCode = `
+//loadTable read table from file of fsys
+func loadTable(fsys fs.FS, file string, t interface {
+	Load(r io.Reader, opts ...gocsv.Option) error
+}, opts []gocsv.Option) error {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := t.Load(f, opts...); err != nil {
+		return fmt.Errorf("load %v: %w", file, err)
+	}
+	return nil
+}
+`
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/foolin/gocsv"
+)
+
+func TestFieldTagComma(t *testing.T) {
+	columns := []gocsv.Column{{Name: "id", Kind: "int"}, {Name: "a,b", Kind: "string"}}
+	if _, err := goBackend.table("t.csv", "T", columns, ""); err == nil {
+		t.Fatal("column with comma is generated")
+	}
+}
+
+func TestQuotedKey(t *testing.T) {
+	key := `id"50%v`
+	columns := []gocsv.Column{{Name: key, Kind: "int"}, {Name: "name", Kind: "string"}}
+	body, err := goBackend.table("t.csv", "T", columns, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := goBackend.file("data", "t.csv", headerHash(columns), body, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`gocsv.ReadMapContext(context.Background(), r, "id\"50%v", &m, opts...)`,
+		`fmt.Errorf("duplicate %v %v", "id\"50%v", row.Id50V)`,
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Fatalf("code has no %v:\n%s", want, code)
+		}
+	}
+}
+
+func TestTargetLoaders(t *testing.T) {
+	columns := []gocsv.Column{{Name: "id", Kind: "int"}, {Name: "name", Kind: "string"}}
+	want := `opts = append([]gocsv.Option{gocsv.WithLayout(gocsv.TargetLayout), gocsv.WithTarget("c")}, opts...)`
+	for _, tt := range []struct {
+		target string
+		n      int
+	}{{"", 0}, {"client", 3}} {
+		*target = tt.target
+		body, err := goBackend.table("t.csv", "T", columns, "")
+		*target = ""
+		if err != nil {
+			t.Fatal(err)
+		}
+		//LoadT, LoadTMap and TTable.Load
+		if n := strings.Count(body, want); n != tt.n {
+			t.Fatalf("target %q has %v target options, want %v:\n%v", tt.target, n, tt.n, body)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/foolin/gocsv"
+)
+
+//headerExt extension of header template written by -infer
+const headerExt = ".header.csv"
+
+//inferHeaders write header template of csv file or of each csv file of dir, outpath is the template or its dir
+func inferHeaders(csvpath string, outpath string, isDir bool, isUtf8 bool) error {
+	if !isDir {
+		if outpath == "" {
+			outpath = path.Dir(csvpath)
+		}
+		if filepath.Ext(outpath) != ".csv" {
+			outpath = path.Join(outpath, filename(csvpath)+headerExt)
+		}
+		return inferHeader(csvpath, outpath, isUtf8)
+	}
+	infos, err := ioutil.ReadDir(csvpath)
+	if err != nil {
+		return err
+	}
+	if outpath == "" {
+		outpath = csvpath
+	}
+	errs := make([]error, 0)
+	for _, info := range infos {
+		//templates of last run
+		if filepath.Ext(info.Name()) != ".csv" || strings.HasSuffix(info.Name(), headerExt) {
+			continue
+		}
+		err := inferHeader(path.Join(csvpath, info.Name()), path.Join(outpath, filename(info.Name())+headerExt), isUtf8)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("generator file: %v error: %v", info.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//inferHeader write description, field name and kind rows of csv with field name row only,
+//kinds are proposed by its values, the template has encoding of csv
+func inferHeader(csvfile string, outfile string, isUtf8 bool) error {
+	columns, err := gocsv.InferSchema(csvfile, !isUtf8)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gocsv.WriteHeaderTo(&buf, !isUtf8, columns); err != nil {
+		return err
+	}
+	return writeFile(outfile, buf.Bytes())
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/foolin/gocsv"
+)
+
+//protoBackend proto3 schema of rows written by gocsv.WriteProto or csv2proto
+var protoBackend = &backend{ext: ".proto", table: protoTable, all: protoTables, file: protoFile}
+
+//protoTable message of row and message XTable of rows
+func protoTable(source string, typename string, columns []gocsv.Column, pk string) (string, error) {
+	typename = identifier(typename, "Table")
+	code, err := gocsv.ProtoMessage(typename, columns)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\n// %v rows of %v, %vTable is written by csv2proto\n%v", typename, source, typename, code), nil
+}
+
+//protoTables messages of single file have no Tables
+func protoTables(typenames []string, sources []string) (string, error) {
+	return "", nil
+}
+
+//protoFile proto3 file of package dir
+func protoFile(dir string, source string, hash string, body string, single bool) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by github.com/foolin/gocsv/tools/generator. DO NOT EDIT.\n// source: %v\n// source header sha256: %v\n\n", source, hash)
+	fmt.Fprintf(&buf, "syntax = \"proto3\";\n\npackage %v;\n%v", packageName(dir), body)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/foolin/gocsv"
+)
+
+//tsBackend typescript interfaces of json written by csv2json -typed
+var tsBackend = &backend{ext: ".ts", table: tsTable, all: tsTables, file: tsFile}
+
+//tsType typescript type of kind, enum is union of its values and "" if it is not required,
+//kind of reference column without kind is string
+func tsType(column gocsv.Column) (string, error) {
+	if column.Kind == "" {
+		return "string", nil
+	}
+	k, err := gocsv.LookupKind(column.Kind)
+	if err != nil {
+		return "", fmt.Errorf("column \"%v\": %v", column.Name, err)
+	}
+	kind, err := tsKindType(k.Name)
+	if err != nil {
+		return "", err
+	}
+	//empty value of enum column which is not required
+	if k.Values != nil && !column.Required {
+		kind = kind + ` | ""`
+	}
+	return kind, nil
+}
+
+func tsKindType(name string) (string, error) {
+	k, err := gocsv.LookupKind(name)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(name, "[]") {
+		elem, err := tsKindType(name[2:])
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " ") {
+			return "(" + elem + ")[]", nil
+		}
+		return elem + "[]", nil
+	}
+	if k.Type.Kind() == reflect.Map {
+		i := strings.Index(name, "]")
+		key, err := tsKindType(name[len("map["):i])
+		if err != nil {
+			return "", err
+		}
+		elem, err := tsKindType(name[i+1:])
+		if err != nil {
+			return "", err
+		}
+		return "Record<" + key + ", " + elem + ">", nil
+	}
+	if k.Values != nil {
+		values := make([]string, len(k.Values))
+		for i, v := range k.Values {
+			values[i] = strconv.Quote(v)
+		}
+		return strings.Join(values, " | "), nil
+	}
+	//json of time is RFC3339 string
+	if k.Type == reflect.TypeOf(time.Time{}) {
+		return "string", nil
+	}
+	switch k.Type.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.String:
+		return "string", nil
+	}
+	return "", fmt.Errorf("kind \"%v\" is not supported by typescript", name)
+}
+
+//tsProperty property name, it is quoted if it is not an identifier
+func tsProperty(name string) string {
+	for i, c := range name {
+		if !(unicode.IsLetter(c) || c == '_' || c == '$' || i > 0 && unicode.IsDigit(c)) {
+			return strconv.Quote(name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+//tsAccess property access of name
+func tsAccess(name string) string {
+	if p := tsProperty(name); p != name {
+		return "[" + p + "]"
+	}
+	return "." + name
+}
+
+//tsDoc jsdoc comment of description
+func tsDoc(desc string, indent string) string {
+	desc = strings.TrimSpace(strings.Replace(desc, "*/", "* /", -1))
+	if desc == "" {
+		return ""
+	}
+	lines := strings.Split(desc, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + strings.TrimRight(lines[0], "\r ") + " */\n"
+	}
+	ret := indent + "/**\n"
+	for _, line := range lines {
+		ret = ret + indent + " * " + strings.TrimRight(line, "\r ") + "\n"
+	}
+	return ret + indent + " */\n"
+}
+
+//tsTable interface of row and record keyed by primary key
+func tsTable(source string, typename string, columns []gocsv.Column, pk string) (string, error) {
+	key, err := primaryKey(columns, pk)
+	if err != nil {
+		return "", err
+	}
+	typename = identifier(typename, "Table")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n/** %v rows of %v */\nexport interface %v {\n", typename, source, typename)
+	for _, column := range columns {
+		kind, err := tsType(column)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(tsDoc(column.Desc, "  "))
+		fmt.Fprintf(&buf, "  %v: %v;\n", tsProperty(column.Name), kind)
+	}
+	buf.WriteString("}\n")
+	keyType, err := tsType(key)
+	if err != nil {
+		return "", err
+	}
+	if keyType == "boolean" || strings.HasSuffix(keyType, "[]") {
+		return "", fmt.Errorf("primary key column \"%v\" of %v cannot be key of Record", key.Name, keyType)
+	}
+	r := strings.NewReplacer("{{X}}", typename, "{{key}}", key.Name, "{{F}}", tsAccess(key.Name), "{{K}}", keyType)
+	buf.WriteString(r.Replace(tsRecordCode))
+	return buf.String(), nil
+}
+
+const tsRecordCode = `
+/** {{X}}Record rows of {{X}} keyed by {{key}} */
+export type {{X}}Record = Record<{{K}}, {{X}}>;
+
+/** to{{X}}Record rows of csv2json keyed by {{key}}, it throws on duplicate {{key}} */
+export function to{{X}}Record(rows: {{X}}[]): {{X}}Record {
+  const record = {} as {{X}}Record;
+  for (const row of rows) {
+    if (row{{F}} in record) {
+      throw new Error("duplicate {{key}} " + row{{F}});
+    }
+    record[row{{F}}] = row;
+  }
+  return record;
+}
+`
+
+//tsTables Tables of json object written by csv2json -typed -out xxx.json, its keys are csv file names
+func tsTables(typenames []string, sources []string) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\n/** Tables all tables of json written by csv2json -typed -out xxx.json */\nexport interface Tables {\n")
+	for i, typename := range typenames {
+		fmt.Fprintf(&buf, "  %v: %v[];\n", tsProperty(filename(sources[i])), typename)
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+//tsFile typescript module
+func tsFile(dir string, source string, hash string, body string, single bool) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by github.com/foolin/gocsv/tools/generator. DO NOT EDIT.\n// source: %v\n// source header sha256: %v\n", source, hash)
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+//Package convert walks csv files for converter tools, e.g. csv2proto and csv2lua
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//Files csv file of csvpath, or csv files of dir csvpath
+func Files(csvpath string) ([]string, error) {
+	info, err := os.Stat(csvpath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{csvpath}, nil
+	}
+	infos, err := ioutil.ReadDir(csvpath)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".csv" {
+			files = append(files, path.Join(csvpath, info.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no csv file in dir: %v", csvpath)
+	}
+	return files, nil
+}
+
+//Run convert each csv file of csvpath to outpath/name+ext, outpath is dir of csv files if it is empty,
+//it stops at the first error
+func Run(csvpath string, outpath string, ext string, convert func(csvfile string, outfile string) error) error {
+	files, err := Files(csvpath)
+	if err != nil {
+		return err
+	}
+	if outpath == "" {
+		outpath = filepath.Dir(files[0])
+	}
+	if err := os.MkdirAll(outpath, 0755); err != nil {
+		return err
+	}
+	for _, csvfile := range files {
+		name := strings.TrimSuffix(filepath.Base(csvfile), filepath.Ext(csvfile))
+		outfile := path.Join(outpath, name+ext)
+		if err := convert(csvfile, outfile); err != nil {
+			return fmt.Errorf("write file: %v error: %v", outfile, err)
+		}
+		log.Printf("write file: %v", outfile)
+	}
+	return nil
+}
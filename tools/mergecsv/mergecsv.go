@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/foolin/gocsv"
 	"os"
 	"strings"
 	"log"
@@ -8,7 +9,6 @@ import (
 	"flag"
 	"io/ioutil"
 	"path"
-	"unicode"
 	"encoding/json"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
@@ -80,8 +80,8 @@ func main() {
 		}
 
 	} else {
-		name := upper(filename(fileInfo.Name()));
-		byteContent, err := readFile(path.Join(*csvpath, fileInfo.Name()), true)
+		name := gocsv.CamelCase(filename(fileInfo.Name()));
+		byteContent, err := readFile(*csvpath, *gbk)
 		if err != nil {
 			log.Fatalf("read csv error: %v", err)
 			return
@@ -118,6 +118,11 @@ func main() {
 
 
 func readFile(file string, isGbk bool) ([]byte, error){
+	//check constraints of kind row
+	err := gocsv.Validate(file, isGbk)
+	if err != nil {
+		return nil, err
+	}
 	fi, err := os.Open(file)
 	if err != nil {
 		return nil, err
@@ -155,28 +160,6 @@ func writeFile(outFile string, content []byte) error  {
 	return nil
 }
 
-func upper(str string) string {
-	if str == "" {
-		return str
-	}
-	ret := make([]rune, 0)
-	isNeedUpper := true        //首字母大写
-	for _, c := range str {
-		//log.Printf("%#v\n", c)
-		if c == '_' {
-			isNeedUpper = true        //下划线大写
-			continue
-		}
-		if isNeedUpper {
-			ret = append(ret, unicode.ToUpper(c))
-		} else {
-			ret = append(ret, c)
-		}
-		isNeedUpper = false
-	}
-	return string(ret)
-}
-
 func filename(filename string) string {
 	name := filepath.Base(filename)
 	return strings.TrimSuffix(name, filepath.Ext(filename))
@@ -0,0 +1,148 @@
+package gocsv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//ValidationError value breaks rule of column
+type ValidationError struct {
+	Name   string //file name
+	Line   int
+	Column string
+	Value  string
+	Rule   string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("Csv line %v column \"%v\" value \"%v\" breaks rule %v", e.Line, e.Column, e.Value, e.Rule)
+	}
+	return fmt.Sprintf("Csv %v line %v column \"%v\" value \"%v\" breaks rule %v", e.Name, e.Line, e.Column, e.Value, e.Rule)
+}
+
+//rule validation rule of value
+type rule struct {
+	name     string //e.g. "min=1"
+	check    func(value string, v reflect.Value) bool
+	required bool //only required checks empty value
+}
+
+//parseRule parse tag option, e.g. required, min=1, max=10, len=3, oneof=a b c, regex=^\d+$
+func parseRule(opt string) (*rule, error) {
+	key, arg := opt, ""
+	if i := strings.Index(opt, "="); i >= 0 {
+		key, arg = trim(opt[:i]), opt[i+1:]
+	}
+	r := &rule{name: opt}
+	switch key {
+	case "required":
+		r.required = true
+		r.check = func(value string, v reflect.Value) bool {
+			return value != ""
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(trim(arg), 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Rule %v is invalid, it must be number", opt))
+		}
+		isMin := key == "min"
+		r.check = func(value string, v reflect.Value) bool {
+			n := number(value, v)
+			if isMin {
+				return n >= limit
+			}
+			return n <= limit
+		}
+	case "len":
+		n, err := strconv.Atoi(trim(arg))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Rule %v is invalid, it must be integer", opt))
+		}
+		r.check = func(value string, v reflect.Value) bool {
+			return length(value, v) == n
+		}
+	case "oneof":
+		values := strings.Fields(arg)
+		r.check = func(value string, v reflect.Value) bool {
+			for _, one := range values {
+				if value == one {
+					return true
+				}
+			}
+			return false
+		}
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Rule %v is invalid: %v", opt, err))
+		}
+		r.check = func(value string, v reflect.Value) bool {
+			return re.MatchString(value)
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Tag option \"%v\" is unknown, rules are required, min, max, len, oneof and regex, other options are rest, unique and ref", opt))
+	}
+	return r, nil
+}
+
+//number numeric value, or length of string
+func number(value string, v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return float64(length(value, v))
+}
+
+//length length of string or slice
+func length(value string, v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len()
+	}
+	return utf8.RuneCountInString(value)
+}
+
+//validate check rules of struct fields, values are raw values of fields,
+//empty value is only checked by required, like constraints of kind row
+func (d *elemDecoder) validate(r row, values map[int]string, elmv reflect.Value) error {
+	for idx := range d.tags {
+		value, ok := values[idx]
+		if !ok {
+			continue
+		}
+		for _, rl := range d.tags[idx].rules {
+			if (value == "" && !rl.required) || rl.check(value, elmv.Field(idx)) {
+				continue
+			}
+			return &ValidationError{Line: r.line, Column: d.tags[idx].name, Value: value, Rule: rl.name}
+		}
+	}
+	return nil
+}
+
+//unique check unique fields, it must be called in the order of rows
+func (d *elemDecoder) unique(name string, r row, elmv reflect.Value) error {
+	for idx := range d.tags {
+		lines, ok := d.uniques[idx]
+		if !ok {
+			continue
+		}
+		value := fmt.Sprint(elmv.Field(idx).Interface())
+		if line, ok := lines[value]; ok {
+			return &ValidationError{Name: name, Line: r.line, Column: d.tags[idx].name, Value: value, Rule: fmt.Sprintf("unique (same as line %v)", line)}
+		}
+		lines[value] = r.line
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package gocsv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type ruleMob struct {
+	Name string `csv:"name,required,len=2"`
+	Lv   int    `csv:"lv,min=1"`
+	Code string `csv:"code,oneof=a b"`
+}
+
+func TestValidateEmpty(t *testing.T) {
+	//empty values skip rules other than required
+	var list []ruleMob
+	csv := "a,b,c\nname,lv,code\nstring,int,string\nab,,\n"
+	if err := ReadListContext(context.Background(), strings.NewReader(csv), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Lv != 0 || list[0].Code != "" {
+		t.Fatalf("list: %+v", list)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	for line, want := range map[string]string{
+		",1,a":   "required",
+		"abc,1,": "len=2",
+		"ab,0,":  "min=1",
+		"ab,1,c": "oneof=a b",
+	} {
+		var list []ruleMob
+		csv := "a,b,c\nname,lv,code\nstring,int,string\n" + line + "\n"
+		err := ReadListContext(context.Background(), strings.NewReader(csv), &list)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Rule != want || verr.Line != 4 {
+			t.Fatalf("line %v error: %v, want rule %v", line, err, want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package gocsv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+//WriteList write []struct to csv file, header rows are description, field name and kind,
+//columns of rest field are written after struct fields in their original order, columns of map[string]string rest field are sorted by name,
+//use WithHeader of ReadHeader to keep column order, descriptions and constraints of the read csv
+func WriteList(file string, isGbk bool, list interface{}, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("write csv file: %v, error: %v", file, rerr))
+		}
+	}()
+	fi, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer fi.Close()
+	return writeList(fi, fileOptions(file, isGbk, opts), list)
+}
+
+//WriteListContext write []struct to w
+func WriteListContext(ctx context.Context, w io.Writer, list interface{}, opts ...Option) (err error) {
+	//catch panic
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = errors.New(fmt.Sprintf("write csv error: %v", rerr))
+		}
+	}()
+	return writeList(w, streamOptions(ctx, opts), list)
+}
+
+//WithHeader write columns of WriteList in order of header, e.g. columns of ReadHeader,
+//with their descriptions, kinds, constraints and targets, columns absent from header are written after them
+func WithHeader(header []Column) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+func writeList(w io.Writer, o *options, list interface{}) error {
+	listv := reflect.ValueOf(list)
+	if listv.Kind() == reflect.Ptr {
+		listv = listv.Elem()
+	}
+	if listv.Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf("Cannot write %T, it must be slice", list))
+	}
+	elmt := listv.Type().Elem()
+	if elmt.Kind() == reflect.Ptr {
+		elmt = elmt.Elem()
+	}
+	dec, err := newElemDecoder(elmt, o.mapper)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]reflect.Value, listv.Len())
+	for i := range rows {
+		rows[i] = reflect.Indirect(listv.Index(i))
+		if !rows[i].IsValid() {
+			rows[i] = reflect.New(elmt).Elem()
+		}
+	}
+
+	//header
+	cols, idxs := make([]Column, 0), make([]int, 0)
+	for i, tag := range dec.tags {
+		if tag.rest || tag.ref != "" {
+			continue
+		}
+		kind, ok := kindOf(elmt.Field(i).Type)
+		if !ok {
+			return errors.New(fmt.Sprintf("Cannot write field %v.%v of %v", elmt, elmt.Field(i).Name, elmt.Field(i).Type))
+		}
+		cols = append(cols, Column{Name: tag.name, Kind: kind})
+		idxs = append(idxs, i)
+	}
+	for _, f := range restColumns(dec, rows) {
+		cols = append(cols, Column{Name: f.Name, Kind: f.Kind})
+		//-1 is column of rest field
+		idxs = append(idxs, -1)
+	}
+	cols, idxs = headerOrder(o.header, cols, idxs)
+	descs, names, kinds, targets := make([]string, len(cols)), make([]string, len(cols)), make([]string, len(cols)), make([]string, len(cols))
+	hasTarget := false
+	for i, c := range cols {
+		descs[i], names[i], kinds[i], targets[i] = c.Desc, c.Name, c.kindRow(), c.Target
+		if descs[i] == "" {
+			descs[i] = c.Name
+		}
+		hasTarget = hasTarget || c.Target != ""
+	}
+	lines := [][]string{descs, names, kinds}
+	if hasTarget {
+		lines = append(lines, targets)
+	}
+
+	if o.gbk {
+		tw := transform.NewWriter(w, simplifiedchinese.GBK.NewEncoder())
+		defer tw.Close()
+		w = tw
+	}
+	writer := csv.NewWriter(w)
+	for _, line := range lines {
+		if err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+	for _, elmv := range rows {
+		//检查是否已取消
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		var values map[string]string
+		if dec.rest >= 0 {
+			values = restValues(elmv.Field(dec.rest))
+		}
+		line := make([]string, 0, len(names))
+		for i, idx := range idxs {
+			if idx < 0 {
+				line = append(line, values[names[i]])
+			} else {
+				line = append(line, formatValue(elmv.Field(idx)))
+			}
+		}
+		if err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//headerOrder order cols as header of WithHeader, with its descriptions, constraints and targets,
+//columns absent from header are after them
+func headerOrder(header []Column, cols []Column, idxs []int) ([]Column, []int) {
+	if len(header) == 0 {
+		return cols, idxs
+	}
+	pos := make(map[string]int, len(cols))
+	for i, c := range cols {
+		pos[c.Name] = i
+	}
+	used := make([]bool, len(cols))
+	ordered, orderedIdxs := make([]Column, 0, len(cols)), make([]int, 0, len(idxs))
+	for _, h := range header {
+		i, ok := pos[h.Name]
+		if !ok || used[i] {
+			continue
+		}
+		used[i] = true
+		c := h
+		if c.Kind == "" {
+			c.Kind = cols[i].Kind
+		}
+		ordered = append(ordered, c)
+		orderedIdxs = append(orderedIdxs, idxs[i])
+	}
+	for i, c := range cols {
+		if !used[i] {
+			ordered = append(ordered, c)
+			orderedIdxs = append(orderedIdxs, idxs[i])
+		}
+	}
+	return ordered, orderedIdxs
+}
+
+//restColumns columns of rest field in all rows, in the order they are first seen
+func restColumns(dec *elemDecoder, rows []reflect.Value) []Field {
+	columns := make([]Field, 0)
+	if dec.rest < 0 {
+		return columns
+	}
+	seen := make(map[string]bool)
+	for _, elmv := range rows {
+		rest := elmv.Field(dec.rest)
+		fields := make([]Field, 0, rest.Len())
+		if rest.Type() == restListType {
+			fields = append(fields, rest.Interface().([]Field)...)
+		} else {
+			//map has no order
+			for name := range rest.Interface().(map[string]string) {
+				fields = append(fields, Field{Name: name, Kind: "string"})
+			}
+			sort.Slice(fields, func(i, j int) bool {
+				return fields[i].Name < fields[j].Name
+			})
+		}
+		for _, f := range fields {
+			if seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			if f.Kind == "" {
+				f.Kind = "string"
+			}
+			columns = append(columns, Field{Name: f.Name, Kind: f.Kind})
+		}
+	}
+	return columns
+}
+
+//restValues name => value of rest field
+func restValues(rest reflect.Value) map[string]string {
+	if rest.Type() != restListType {
+		return rest.Interface().(map[string]string)
+	}
+	values := make(map[string]string)
+	for _, f := range rest.Interface().([]Field) {
+		values[f.Name] = f.Value
+	}
+	return values
+}
+
+//formatValue value to csv string
+func formatValue(v reflect.Value) string {
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time))
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(values, ArraySep)
+	case reflect.Map:
+		//map has no order
+		values := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values = append(values, formatValue(iter.Key())+MapSep+formatValue(iter.Value()))
+		}
+		sort.Strings(values)
+		return strings.Join(values, ArraySep)
+	}
+	return v.String()
+}
+
+//writeOutput write to a temp file in dir of out and rename it to out after write succeeds,
+//so a failed write leaves no truncated out and keeps its old content
+func writeOutput(out string, write func(w io.Writer) error) error {
+	fi, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fi.Name())
+	defer fi.Close()
+	if err := write(fi); err != nil {
+		return err
+	}
+	if err := fi.Chmod(0644); err != nil {
+		return err
+	}
+	if err := fi.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fi.Name(), out)
+}
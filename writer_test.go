@@ -0,0 +1,50 @@
+package gocsv
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type writeMob struct {
+	ID   int               `csv:"id"`
+	Name string            `csv:"name"`
+	Rest map[string]string `csv:",rest"`
+}
+
+const writeCsv = "extra,编号,名称,more\nmemo,id,name,lv\nstring,int!,string,int(1..9)\nx,1,a,3\ny,2,b,4\n"
+
+func TestWriteListRest(t *testing.T) {
+	var list []writeMob
+	if err := ReadListContext(context.Background(), strings.NewReader(writeCsv), &list); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteListContext(context.Background(), &buf, list); err != nil {
+		t.Fatal(err)
+	}
+	//rest columns of map are after struct fields and sorted by name
+	want := "id,name,lv,memo\nid,name,lv,memo\nint,string,string,string\n1,a,3,x\n2,b,4,y\n"
+	if buf.String() != want {
+		t.Fatalf("csv:\n%v\nwant:\n%v", buf.String(), want)
+	}
+}
+
+func TestWriteListHeader(t *testing.T) {
+	cols, err := ReadHeaderContext(context.Background(), strings.NewReader(writeCsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []writeMob
+	if err := ReadListContext(context.Background(), strings.NewReader(writeCsv), &list); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteListContext(context.Background(), &buf, list, WithHeader(cols)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != writeCsv {
+		t.Fatalf("csv:\n%v\nwant:\n%v", buf.String(), writeCsv)
+	}
+}